@@ -0,0 +1,234 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"time"
+
+	over "github.com/Trendyol/overlog"
+	"github.com/cgi-fr/pimo/pkg/cachedump"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dumpInputFormat  string
+	dumpOutputFormat string
+	dumpEncrypt      bool
+)
+
+func newCacheCommand() *cobra.Command {
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect and transform cache dumps written by the dump-cache flag",
+	}
+	cacheCmd.PersistentFlags().StringVar(&dumpInputFormat, "input-format", "", "format of the dumps to read : jsonline or csv (default from the file extension)")
+	cacheCmd.PersistentFlags().StringVar(&dumpOutputFormat, "output-format", cachedump.JSONLine, "format of the dump written to the standard output : jsonline or csv")
+	cacheCmd.PersistentFlags().BoolVar(&dumpEncrypt, "encrypt", false, "encrypt the dump written to the standard output with the cache key")
+
+	var (
+		sampleSize int
+		sampleSeed int64
+	)
+	inspectCmd := &cobra.Command{
+		Use:   "inspect <dump>",
+		Short: "Count entries and distinct values, list values shared by several keys and show a sample of entries",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			runCache("inspect", func() error {
+				entries, err := readDump(args[0])
+				if err != nil {
+					return err
+				}
+				report, err := cachedump.Inspect(entries, sampleSize, sampleSeed)
+				if err != nil {
+					return err
+				}
+				content, err := json.Marshal(report)
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(content))
+				return nil
+			})
+		},
+	}
+	inspectCmd.Flags().IntVar(&sampleSize, "sample", 5, "number of entries in the sample")
+	inspectCmd.Flags().Int64Var(&sampleSeed, "seed", time.Now().UnixNano(), "seed of the random sample")
+
+	var conflict string
+	mergeCmd := &cobra.Command{
+		Use:   "merge <dump> <dump>",
+		Short: "Merge two dumps, conflicts are keys with different values",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			runCache("merge", func() error {
+				first, err := readDump(args[0])
+				if err != nil {
+					return err
+				}
+				second, err := readDump(args[1])
+				if err != nil {
+					return err
+				}
+				merged, err := cachedump.Merge(first, second, conflict)
+				if err != nil {
+					return err
+				}
+				return writeDump(merged)
+			})
+		},
+	}
+	mergeCmd.Flags().StringVar(&conflict, "conflict", cachedump.Fail, "value kept on conflicts : first, second or error")
+
+	invertCmd := &cobra.Command{
+		Use:   "invert <dump>",
+		Short: "Swap keys and values of a dump, to reverse a pseudonymization",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			runCache("invert", func() error {
+				entries, err := readDump(args[0])
+				if err != nil {
+					return err
+				}
+				inverted, err := cachedump.Invert(entries)
+				if err != nil {
+					return err
+				}
+				return writeDump(inverted)
+			})
+		},
+	}
+
+	var keyPattern string
+	filterCmd := &cobra.Command{
+		Use:   "filter <dump>",
+		Short: "Keep the entries with a key matching a regular expression",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			runCache("filter", func() error {
+				pattern, err := regexp.Compile(keyPattern)
+				if err != nil {
+					return err
+				}
+				entries, err := readDump(args[0])
+				if err != nil {
+					return err
+				}
+				filtered, err := cachedump.Filter(entries, pattern)
+				if err != nil {
+					return err
+				}
+				return writeDump(filtered)
+			})
+		},
+	}
+	filterCmd.Flags().StringVar(&keyPattern, "key", "", "regular expression matched against the keys")
+
+	convertCmd := &cobra.Command{
+		Use:   "convert <dump>",
+		Short: "Convert a dump to the output format",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			runCache("convert", func() error {
+				entries, err := readDump(args[0])
+				if err != nil {
+					return err
+				}
+				return writeDump(entries)
+			})
+		},
+	}
+
+	cacheCmd.AddCommand(inspectCmd, mergeCmd, invertCmd, filterCmd, convertCmd)
+	return cacheCmd
+}
+
+func runCache(action string, command func() error) {
+	initLog()
+
+	over.AddGlobalFields("context")
+	over.MDC().Set("context", "cache-"+action)
+	if err := command(); err != nil {
+		log.Err(err).Msg("Cannot " + action + " cache dump")
+		log.Warn().Int("return", 1).Msg("End PIMO")
+		os.Exit(1)
+	}
+	log.Info().Int("return", 0).Msg("End PIMO")
+	os.Exit(0)
+}
+
+// readDump reads a dump file, or the standard input if the path is -, encrypted dumps are decrypted with the cache key
+func readDump(path string) ([]cachedump.Entry, error) {
+	format := dumpInputFormat
+	if format == "" {
+		format = cachedump.FormatOf(path)
+	}
+
+	var (
+		content []byte
+		err     error
+	)
+	if path == "-" {
+		content, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		content, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := cachedump.LoadKey(cacheKeyFromEnv, cacheKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if content, err = cachedump.Decrypt(content, key); err != nil {
+		return nil, err
+	}
+	return cachedump.Read(bytes.NewReader(content), format)
+}
+
+// writeDump writes a dump to the standard output, encrypted with the cache key if the encrypt flag is set
+func writeDump(entries []cachedump.Entry) error {
+	var content bytes.Buffer
+	if err := cachedump.Write(&content, dumpOutputFormat, entries); err != nil {
+		return err
+	}
+
+	result := content.Bytes()
+	if dumpEncrypt {
+		key, err := cachedump.LoadKey(cacheKeyFromEnv, cacheKeyFile)
+		if err != nil {
+			return err
+		}
+		if key == nil {
+			return fmt.Errorf("encrypt flag requires a cache key")
+		}
+		if result, err = cachedump.Encrypt(result, key); err != nil {
+			return err
+		}
+	}
+	_, err := os.Stdout.Write(result)
+	return err
+}
@@ -18,7 +18,9 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"regexp"
 	"runtime"
@@ -28,19 +30,35 @@
 	over "github.com/Trendyol/overlog"
 	"github.com/cgi-fr/pimo/internal/app/pimo"
 	"github.com/cgi-fr/pimo/pkg/add"
+	"github.com/cgi-fr/pimo/pkg/address"
 	"github.com/cgi-fr/pimo/pkg/addtransient"
+	"github.com/cgi-fr/pimo/pkg/anonymity"
+	"github.com/cgi-fr/pimo/pkg/cachedump"
+	"github.com/cgi-fr/pimo/pkg/checkpoint"
 	"github.com/cgi-fr/pimo/pkg/command"
 	"github.com/cgi-fr/pimo/pkg/constant"
+	"github.com/cgi-fr/pimo/pkg/creditcard"
 	"github.com/cgi-fr/pimo/pkg/dateparser"
 	"github.com/cgi-fr/pimo/pkg/duration"
+	"github.com/cgi-fr/pimo/pkg/email"
+	"github.com/cgi-fr/pimo/pkg/embedded"
 	"github.com/cgi-fr/pimo/pkg/ff1"
 	"github.com/cgi-fr/pimo/pkg/fluxuri"
 	"github.com/cgi-fr/pimo/pkg/fromjson"
+	"github.com/cgi-fr/pimo/pkg/generalize"
+	"github.com/cgi-fr/pimo/pkg/generate"
 	"github.com/cgi-fr/pimo/pkg/hash"
+	"github.com/cgi-fr/pimo/pkg/iban"
 	"github.com/cgi-fr/pimo/pkg/increment"
+	"github.com/cgi-fr/pimo/pkg/insee"
+	"github.com/cgi-fr/pimo/pkg/ip"
 	"github.com/cgi-fr/pimo/pkg/jsonline"
 	"github.com/cgi-fr/pimo/pkg/luhn"
+	"github.com/cgi-fr/pimo/pkg/mac"
+	"github.com/cgi-fr/pimo/pkg/metrics"
 	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/cgi-fr/pimo/pkg/nir"
+	"github.com/cgi-fr/pimo/pkg/phone"
 	"github.com/cgi-fr/pimo/pkg/pipe"
 	"github.com/cgi-fr/pimo/pkg/randdate"
 	"github.com/cgi-fr/pimo/pkg/randdura"
@@ -52,9 +70,13 @@
 	"github.com/cgi-fr/pimo/pkg/regex"
 	"github.com/cgi-fr/pimo/pkg/remove"
 	"github.com/cgi-fr/pimo/pkg/replacement"
+	"github.com/cgi-fr/pimo/pkg/siren"
 	"github.com/cgi-fr/pimo/pkg/statistics"
 	"github.com/cgi-fr/pimo/pkg/templateeach"
 	"github.com/cgi-fr/pimo/pkg/templatemask"
+	"github.com/cgi-fr/pimo/pkg/textredact"
+	"github.com/cgi-fr/pimo/pkg/uri"
+	"github.com/cgi-fr/pimo/pkg/urlmask"
 	"github.com/cgi-fr/pimo/pkg/weightedchoice"
 	"github.com/mattn/go-isatty"
 	"github.com/rs/zerolog"
@@ -79,11 +101,19 @@
 	maskingFile      string
 	cachesToDump     map[string]string
 	cachesToLoad     map[string]string
+	cacheKeyFromEnv  string
+	cacheKeyFile     string
 	skipLineOnError  bool
 	skipFieldOnError bool
 	maskingOneLiner  []string
 	repeatUntil      string
 	repeatWhile      string
+	metricsAddr      string
+	checkpointFile   string
+	checkpointEvery  int
+	resume           bool
+	validateOutput   string
+	datasetDirs      []string
 )
 
 func main() {
@@ -110,11 +140,19 @@ func main() {
 	rootCmd.PersistentFlags().StringVarP(&maskingFile, "config", "c", "masking.yml", "name and location of the masking-config file")
 	rootCmd.PersistentFlags().StringToStringVar(&cachesToDump, "dump-cache", map[string]string{}, "path for dumping cache into file")
 	rootCmd.PersistentFlags().StringToStringVar(&cachesToLoad, "load-cache", map[string]string{}, "path for loading cache from file")
+	rootCmd.PersistentFlags().StringVar(&cacheKeyFromEnv, "cache-key-from-env", "", "name of an environment variable containing a base64 AES key to encrypt dumped caches and decrypt loaded caches")
+	rootCmd.PersistentFlags().StringVar(&cacheKeyFile, "cache-key-file", "", "path of a file containing a base64 AES key to encrypt dumped caches and decrypt loaded caches")
 	rootCmd.PersistentFlags().BoolVar(&skipLineOnError, "skip-line-on-error", false, "skip a line if an error occurs while masking a field")
 	rootCmd.PersistentFlags().BoolVar(&skipFieldOnError, "skip-field-on-error", false, "remove a field if an error occurs while masking this field")
 	rootCmd.PersistentFlags().StringArrayVarP(&maskingOneLiner, "mask", "m", []string{}, "one liner masking")
 	rootCmd.PersistentFlags().StringVar(&repeatUntil, "repeat-until", "", "mask each input repeatedly until the given condition is met")
 	rootCmd.PersistentFlags().StringVar(&repeatWhile, "repeat-while", "", "mask each input repeatedly while the given condition is met")
+	rootCmd.PersistentFlags().StringVar(&checkpointFile, "checkpoint", "", "path of a file to periodically save the progress of the run")
+	rootCmd.PersistentFlags().IntVar(&checkpointEvery, "checkpoint-interval", 1000, "number of input lines between two checkpoints")
+	rootCmd.PersistentFlags().BoolVar(&resume, "resume", false, "resume an interrupted run from the checkpoint file")
+	rootCmd.PersistentFlags().StringVar(&validateOutput, "validate-output", "", "check each output line against a JSON schema file, invalid lines follow the skip-line-on-error and skip-field-on-error flags")
+	rootCmd.PersistentFlags().StringArrayVar(&datasetDirs, "dataset-dir", []string{}, "directory of datasets available with the pimo:// scheme, repeat the flag for each directory")
+	rootCmd.PersistentFlags().StringVar(&metricsAddr, "metrics-addr", "", "expose Prometheus metrics on this address while the pipeline runs (e.g. :9090)")
 
 	rootCmd.AddCommand(&cobra.Command{
 		Use: "jsonschema",
@@ -127,6 +165,26 @@ func main() {
 		},
 	})
 
+	var (
+		quasiIdentifiers []string
+		anonymityK       int
+		anonymityAction  string
+		anonymityReport  string
+	)
+	anonymityCmd := &cobra.Command{
+		Use:   "anonymity-report",
+		Short: "Check that each combination of quasi-identifiers appears at least k times in jsonlines",
+		Run: func(cmd *cobra.Command, args []string) {
+			runAnonymityReport(anonymity.Config{QuasiIdentifiers: quasiIdentifiers, K: anonymityK, Action: anonymityAction}, anonymityReport)
+		},
+	}
+	anonymityCmd.Flags().StringArrayVarP(&quasiIdentifiers, "quasi-identifier", "q", []string{}, "jsonpath of a quasi-identifier, repeat the flag for each quasi-identifier")
+	anonymityCmd.Flags().IntVarP(&anonymityK, "k", "k", 2, "minimum number of records sharing the same quasi-identifiers")
+	anonymityCmd.Flags().StringVar(&anonymityAction, "action", "", "write records to the output and apply an action to records in classes smaller than k : suppress or generalize")
+	anonymityCmd.Flags().StringVar(&anonymityReport, "report", "", "path of a file to write the report, used with the action flag")
+	rootCmd.AddCommand(anonymityCmd)
+	rootCmd.AddCommand(newCacheCommand())
+
 	if err := rootCmd.Execute(); err != nil {
 		log.Err(err).Msg("Error when executing command")
 		os.Exit(1)
@@ -143,6 +201,13 @@ func run() {
 		Bool("empty-input", emptyInput).
 		Interface("dump-cache", cachesToDump).
 		Interface("load-cache", cachesToLoad).
+		Str("cache-key-from-env", cacheKeyFromEnv).
+		Str("cache-key-file", cacheKeyFile).
+		Str("metrics-addr", metricsAddr).
+		Str("checkpoint", checkpointFile).
+		Bool("resume", resume).
+		Str("validate-output", validateOutput).
+		Strs("dataset-dir", datasetDirs).
 		Msg("Start PIMO")
 
 	var source model.Source
@@ -155,6 +220,46 @@ func run() {
 		source = jsonline.NewSource(os.Stdin)
 	}
 
+	if resume && checkpointFile == "" {
+		log.Error().Msg("Cannot use resume flag without checkpoint flag")
+		log.Warn().Int("return", 1).Msg("End PIMO")
+		os.Exit(1)
+	}
+
+	// checkpoints contain the content of caches, they are encrypted with the key of caches
+	cacheKey, err := cachedump.LoadKey(cacheKeyFromEnv, cacheKeyFile)
+	if err != nil {
+		log.Err(err).Msg("Cannot read cache key")
+		log.Warn().Int("return", 1).Msg("End PIMO")
+		os.Exit(1)
+	}
+
+	var (
+		recorder *checkpoint.Recorder
+		restored *checkpoint.Checkpoint
+		offset   int
+	)
+	if resume {
+		saved, err := checkpoint.Load(checkpointFile, cacheKey)
+		switch {
+		case os.IsNotExist(err):
+			log.Info().Str("checkpoint", checkpointFile).Msg("No checkpoint found, starting from the beginning")
+		case err != nil:
+			log.Err(err).Str("checkpoint", checkpointFile).Msg("Cannot load checkpoint")
+			log.Warn().Int("return", 1).Msg("End PIMO")
+			os.Exit(1)
+		default:
+			restored = &saved
+			offset = saved.InputLine
+			source = checkpoint.Skip(source, offset)
+			log.Info().Str("checkpoint", checkpointFile).Int("input-line", offset).Int("output-line", saved.OutputLine).Msg("Resume from checkpoint")
+		}
+	}
+	if checkpointFile != "" {
+		recorder = checkpoint.NewRecorder(checkpointFile, checkpointEvery, cacheKey)
+		source = recorder.Source(source, offset)
+	}
+
 	if repeatUntil != "" && repeatWhile != "" {
 		log.Error().Msg("Cannot use repeatUntil and repeatWhile flags together")
 		log.Warn().Int("return", 1).Msg("End PIMO")
@@ -172,12 +277,17 @@ func run() {
 		source = model.NewTempSource(source)
 	}
 
-	pipeline := model.NewPipeline(source).
-		Process(model.NewCounterProcessWithCallback("input-line", 0, updateContext)).
+	var exporter *metrics.Exporter
+	pipeline := model.NewPipeline(source)
+	if metricsAddr != "" {
+		exporter = metrics.NewExporter()
+		pipeline = pipeline.Process(exporter.InputProcess())
+	}
+	pipeline = pipeline.
+		Process(model.NewCounterProcessWithCallback("input-line", offset, updateContext)).
 		Process(model.NewRepeaterProcess(iteration))
 	over.AddGlobalFields("input-line")
 	var (
-		err    error
 		caches map[string]model.Cache
 		pdef   model.Definition
 	)
@@ -198,6 +308,10 @@ func run() {
 		os.Exit(1)
 	}
 
+	for _, dir := range append(datasetDirs, pdef.Datasets...) {
+		uri.RegisterDirectory(dir)
+	}
+
 	pipeline, caches, err = model.BuildPipeline(pipeline, pdef, nil)
 	if err != nil {
 		log.Error().Err(err).Msg("Cannot build pipeline")
@@ -205,8 +319,19 @@ func run() {
 		os.Exit(1)
 	}
 
+	var generator *generate.Process
+	if len(pdef.Entities) > 0 {
+		generator, err = generate.NewProcess(pdef.Entities, pdef.Seed, caches)
+		if err != nil {
+			log.Error().Err(err).Msg("Cannot build pipeline")
+			log.Warn().Int("return", 1).Msg("End PIMO")
+			os.Exit(1)
+		}
+		pipeline = pipeline.Process(generator)
+	}
+
 	if repeatCondition != "" {
-		processor, err := model.NewRepeaterUntilProcess(source.(*model.TempSource), repeatCondition, repeatConditionMode)
+		processor, err := model.NewRepeaterUntilProcess(source.(*model.TempSource), repeatCondition, repeatConditionMode, pdef.Seed, caches)
 		if err != nil {
 			log.Error().Err(err).Msg("Cannot build pipeline")
 			log.Warn().Int("return", 1).Msg("End PIMO")
@@ -215,6 +340,19 @@ func run() {
 		pipeline = pipeline.Process(processor)
 	}
 
+	if validateOutput != "" {
+		processor, err := model.NewValidateProcess(validateOutput)
+		if err != nil {
+			log.Err(err).Str("validate-output", validateOutput).Msg("Cannot load output schema")
+			log.Warn().Int("return", 1).Msg("End PIMO")
+			os.Exit(1)
+		}
+		pipeline = pipeline.Process(processor)
+	}
+
+	// init stats to zero, loading caches can already evict entries
+	statistics.Reset()
+
 	for name, path := range cachesToLoad {
 		cache, ok := caches[name]
 		if !ok {
@@ -222,7 +360,7 @@ func run() {
 			log.Warn().Int("return", 2).Msg("End PIMO")
 			os.Exit(2)
 		}
-		err = pimo.LoadCache(name, cache, path)
+		err = pimo.LoadCache(name, cache, path, cacheKey)
 		if err != nil {
 			log.Err(err).Str("cache-name", name).Str("cache-path", path).Msg("Cannot load cache")
 			log.Warn().Int("return", 3).Msg("End PIMO")
@@ -230,12 +368,57 @@ func run() {
 		}
 	}
 
-	// init stats and time measure to zero
-	statistics.Reset()
+	stateful := model.GetStatefulMasks()
+	if generator != nil {
+		// cardinalities of entities are saved with masks
+		stateful = map[string]model.Stateful{"entities": generator}
+		for name, mask := range model.GetStatefulMasks() {
+			stateful[name] = mask
+		}
+	}
+
+	if restored != nil {
+		if err = restored.Restore(stateful, caches); err != nil {
+			log.Err(err).Str("checkpoint", checkpointFile).Msg("Cannot restore checkpoint")
+			log.Warn().Int("return", 3).Msg("End PIMO")
+			os.Exit(3)
+		}
+	}
+
+	if recorder != nil {
+		recorder.Watch(stateful, caches)
+	}
+
+	// init time measure to zero
 	startTime := time.Now()
 
 	over.AddGlobalFields("output-line")
-	err = pipeline.AddSink(jsonline.NewSinkWithContext(os.Stdout, "output-line")).Run()
+	sink := jsonline.NewSinkWithContext(os.Stdout, "output-line")
+	if recorder != nil {
+		written := 0
+		if restored != nil {
+			written = restored.OutputLine
+		}
+		sink = recorder.Sink(sink, written)
+	}
+	if exporter != nil {
+		exporter.WatchCaches(caches)
+		exporter.Reset()
+		addr, err := exporter.Serve(metricsAddr)
+		if err != nil {
+			log.Err(err).Str("metrics-addr", metricsAddr).Msg("Cannot start metrics listener")
+			log.Warn().Int("return", 1).Msg("End PIMO")
+			os.Exit(1)
+		}
+		log.Info().Str("metrics-addr", addr.String()).Msg("Metrics exposed")
+		sink = exporter.Sink(sink)
+	}
+	err = pipeline.AddSink(sink).Run()
+	if generator != nil {
+		if errClose := generator.Close(); errClose != nil && err == nil {
+			err = errClose
+		}
+	}
 
 	// include duration info and stats in log output
 	duration := time.Since(startTime)
@@ -256,7 +439,7 @@ func run() {
 			log.Warn().RawJSON("stats", stats.ToJSON()).Int("return", 2).Msg("End PIMO")
 			os.Exit(2)
 		}
-		err = pimo.DumpCache(name, cache, path)
+		err = pimo.DumpCache(name, cache, path, cacheKey)
 		if err != nil {
 			log.Err(err).Str("cache-name", name).Str("cache-path", path).Msg("Cannot dump cache")
 			log.Warn().RawJSON("stats", stats.ToJSON()).Int("return", 3).Msg("End PIMO")
@@ -268,10 +451,52 @@ func run() {
 	os.Exit(0)
 }
 
+func runAnonymityReport(conf anonymity.Config, reportFile string) {
+	initLog()
+
+	over.AddGlobalFields("context")
+	over.MDC().Set("context", "anonymity-report")
+	report, err := anonymity.Run(jsonline.NewSource(os.Stdin), os.Stdout, conf)
+	if err != nil {
+		log.Err(err).Msg("Cannot check anonymity")
+		log.Warn().Int("return", 1).Msg("End PIMO")
+		os.Exit(1)
+	}
+
+	content, err := json.Marshal(report)
+	if err != nil {
+		log.Err(err).Msg("Cannot write anonymity report")
+		log.Warn().Int("return", 1).Msg("End PIMO")
+		os.Exit(1)
+	}
+	switch {
+	case conf.Action == "":
+		fmt.Println(string(content))
+	case reportFile != "":
+		if err := ioutil.WriteFile(reportFile, append(content, '\n'), 0o600); err != nil {
+			log.Err(err).Str("report", reportFile).Msg("Cannot write anonymity report")
+			log.Warn().Int("return", 1).Msg("End PIMO")
+			os.Exit(1)
+		}
+	}
+
+	final := report
+	if report.Output != nil {
+		final = *report.Output
+	}
+	if !final.Anonymous {
+		log.Warn().RawJSON("report", content).Int("return", 5).Msg("End PIMO")
+		os.Exit(5)
+	}
+	log.Info().RawJSON("report", content).Int("return", 0).Msg("End PIMO")
+	os.Exit(0)
+}
+
 func injectMaskContextFactories() []model.MaskContextFactory {
 	return []model.MaskContextFactory{
 		fluxuri.Factory,
 		add.Factory,
+		address.Factory,
 		addtransient.Factory,
 		remove.Factory,
 		pipe.Factory,
@@ -288,6 +513,7 @@ func injectMaskFactories() []model.MaskFactory {
 		randomuri.Factory,
 		randomint.Factory,
 		weightedchoice.Factory,
+		weightedchoice.URIFactory,
 		regex.Factory,
 		hash.Factory,
 		randdate.Factory,
@@ -301,6 +527,21 @@ func injectMaskFactories() []model.MaskFactory {
 		dateparser.Factory,
 		ff1.Factory,
 		luhn.Factory,
+		email.Factory,
+		phone.Factory,
+		iban.Factory,
+		iban.BICFactory,
+		nir.Factory,
+		siren.Factory,
+		siren.SIRETFactory,
+		insee.Factory,
+		creditcard.Factory,
+		ip.Factory,
+		mac.Factory,
+		urlmask.Factory,
+		textredact.Factory,
+		embedded.Factory,
+		generalize.Factory,
 	}
 }
 
@@ -18,39 +18,66 @@
 package pimo
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 
 	"github.com/alecthomas/jsonschema"
+	"github.com/cgi-fr/pimo/pkg/cachedump"
 	"github.com/cgi-fr/pimo/pkg/jsonline"
 	"github.com/cgi-fr/pimo/pkg/model"
 )
 
 type CachedMaskEngineFactories func(model.MaskEngine) model.MaskEngine
 
-func DumpCache(name string, cache model.Cache, path string) error {
-	file, err := os.Create(path)
+// DumpCache writes the content of the cache in a jsonline file, encrypted with AES-GCM if a key is given
+func DumpCache(name string, cache model.Cache, path string, key []byte) error {
+	if key == nil {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+		if err != nil {
+			return fmt.Errorf("Cache %s not dump : %s", name, err.Error())
+		}
+		defer file.Close()
+		err = model.NewPipeline(cache.Iterate()).AddSink(jsonline.NewSink(file)).Run()
+		if err != nil {
+			return fmt.Errorf("Cache %s not dump : %s", name, err.Error())
+		}
+		return nil
+	}
+
+	// the whole dump is encrypted at once
+	var content bytes.Buffer
+	err := model.NewPipeline(cache.Iterate()).AddSink(jsonline.NewSink(&content)).Run()
 	if err != nil {
 		return fmt.Errorf("Cache %s not dump : %s", name, err.Error())
 	}
 
-	defer file.Close()
-	err = model.NewPipeline(cache.Iterate()).AddSink(jsonline.NewSink(file)).Run()
+	result, err := cachedump.Encrypt(content.Bytes(), key)
 	if err != nil {
 		return fmt.Errorf("Cache %s not dump : %s", name, err.Error())
 	}
 
+	if err = ioutil.WriteFile(path, result, 0o600); err != nil {
+		return fmt.Errorf("Cache %s not dump : %s", name, err.Error())
+	}
+
 	return nil
 }
 
-func LoadCache(name string, cache model.Cache, path string) error {
+// LoadCache reads the content of the cache from a jsonline file, encrypted dumps are detected and decrypted with the key
+func LoadCache(name string, cache model.Cache, path string, key []byte) error {
 	file, err := os.Open(path)
 	if err != nil {
 		return fmt.Errorf("Cache %s not loaded : %s", name, err.Error())
 	}
 	defer file.Close()
-	err = model.NewPipeline(jsonline.NewSource(file)).AddSink(model.NewSinkToCache(cache)).Run()
+	reader, err := cachedump.NewReader(file, key)
+	if err != nil {
+		return fmt.Errorf("Cache %s not loaded : %s", name, err.Error())
+	}
+	err = model.NewPipeline(jsonline.NewSource(reader)).AddSink(model.NewSinkToCache(cache)).Run()
 	if err != nil {
 		return fmt.Errorf("Cache %s not loaded : %s", name, err.Error())
 	}
@@ -16,3 +16,48 @@
 // along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
 
 package pimo
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpAndLoadEncryptedCache(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	path := filepath.Join(t.TempDir(), "cache.jsonl")
+	cache := model.NewMemCache()
+	cache.Put("alice", "X1")
+
+	assert.Nil(t, DumpCache("names", cache, path, key))
+	content, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(content), "alice")
+
+	assert.NotNil(t, LoadCache("names", model.NewMemCache(), path, nil))
+	assert.NotNil(t, LoadCache("names", model.NewMemCache(), path, []byte("fedcba9876543210")))
+
+	loaded := model.NewMemCache()
+	assert.Nil(t, LoadCache("names", loaded, path, key))
+	value, ok := loaded.Get("alice")
+	assert.True(t, ok)
+	assert.Equal(t, "X1", value)
+}
+
+func TestDumpAndLoadPlainCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.jsonl")
+	cache := model.NewMemCache()
+	cache.Put("alice", "X1")
+
+	assert.Nil(t, DumpCache("names", cache, path, nil))
+	content, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "{\"key\":\"alice\",\"value\":\"X1\"}\n", string(content))
+
+	loaded := model.NewMemCache()
+	assert.Nil(t, LoadCache("names", loaded, path, []byte("0123456789abcdef")))
+	assert.Equal(t, 1, loaded.Len())
+}
@@ -0,0 +1,220 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package address
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+	"unicode"
+
+	"github.com/cgi-fr/pimo/pkg/maskingdata"
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/cgi-fr/pimo/pkg/weightedchoice"
+	"github.com/rs/zerolog/log"
+)
+
+// MaxNumber is the highest street number generated
+const MaxNumber = 150
+
+// MaskEngine writes a coherent address (number, street, postcode, city and country) in the fields of an object
+type MaskEngine struct {
+	locale  string
+	country string
+	fields  model.AddressFieldsType
+	keep    string
+	model.RandState
+	rand    *rand.Rand
+	towns   weightedchoice.Chooser
+	streets weightedchoice.Chooser
+	regions map[string]weightedchoice.Chooser
+}
+
+// NewMask create a MaskEngine picking towns and streets by frequency in the dataset of the locale
+func NewMask(conf model.AddressType, seed int64) (MaskEngine, error) {
+	locale := strings.ToUpper(conf.Locale)
+	if locale == "" {
+		locale = "FR"
+	}
+	dataset, ok := maskingdata.AddressData[locale]
+	if !ok {
+		return MaskEngine{}, fmt.Errorf("no address dataset for locale '%s'", conf.Locale)
+	}
+	if conf.Keep != "" && conf.Keep != "city" && conf.Keep != "region" {
+		return MaskEngine{}, fmt.Errorf("keep should be city or region, not '%s'", conf.Keep)
+	}
+
+	fields := conf.Fields
+	for _, field := range []struct {
+		name         *string
+		defaultValue string
+	}{
+		{&fields.Number, "number"},
+		{&fields.Street, "street"},
+		{&fields.Postcode, "postcode"},
+		{&fields.City, "city"},
+		{&fields.Country, "country"},
+	} {
+		if *field.name == "" {
+			*field.name = field.defaultValue
+		}
+	}
+
+	source := model.NewRandSource(seed)
+	towns := []weightedchoice.Choice{}
+	regions := map[string][]weightedchoice.Choice{}
+	for _, town := range dataset.Towns {
+		choice := weightedchoice.Choice{Item: town, Weight: town.Population}
+		towns = append(towns, choice)
+		region := Region(locale, town.Postcode)
+		regions[region] = append(regions[region], choice)
+	}
+	streets := []weightedchoice.Choice{}
+	for _, street := range dataset.Streets {
+		streets = append(streets, weightedchoice.Choice{Item: street.Value, Weight: street.Weight})
+	}
+
+	regionChoosers := map[string]weightedchoice.Chooser{}
+	for region, choices := range regions {
+		regionChoosers[region] = weightedchoice.NewChooserFromSource(source, choices...)
+	}
+
+	// nolint: gosec
+	return MaskEngine{
+		locale:    locale,
+		country:   dataset.Country,
+		fields:    fields,
+		keep:      conf.Keep,
+		RandState: model.NewRandState(source),
+		rand:      rand.New(source),
+		towns:     weightedchoice.NewChooserFromSource(source, towns...),
+		streets:   weightedchoice.NewChooserFromSource(source, streets...),
+		regions:   regionChoosers,
+	}, nil
+}
+
+// Region returns the part of a postcode identifying its region : the area letters in the United Kingdom,
+// the first digit in Belgium and the first two digits elsewhere (département, province or Leitregion)
+func Region(locale string, postcode string) string {
+	postcode = strings.ToUpper(strings.TrimSpace(postcode))
+	switch locale {
+	case "UK":
+		return leadingLetters(postcode)
+	case "BE":
+		return prefix(postcode, 1)
+	default:
+		return prefix(postcode, 2)
+	}
+}
+
+func leadingLetters(s string) string {
+	for i, r := range s {
+		if !unicode.IsLetter(r) {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+func prefix(s string, n int) string {
+	if len(s) < n {
+		return s
+	}
+	return s[:n]
+}
+
+// MaskContext replace the address fields of the object, or of each object in an array
+func (am MaskEngine) MaskContext(context model.Dictionary, key string, contexts ...model.Dictionary) (model.Dictionary, error) {
+	log.Info().Msg("Mask address")
+	value, _ := context.GetValue(key)
+	switch typed := value.(type) {
+	case []model.Entry:
+		result := make([]model.Entry, len(typed))
+		for i, item := range typed {
+			address, err := am.maskAddress(item)
+			if err != nil {
+				return context, err
+			}
+			result[i] = address
+		}
+		context.Set(key, result)
+	default:
+		address, err := am.maskAddress(value)
+		if err != nil {
+			return context, err
+		}
+		context.Set(key, address)
+	}
+	return context, nil
+}
+
+func (am MaskEngine) maskAddress(value model.Entry) (model.Dictionary, error) {
+	var address model.Dictionary
+	switch typed := value.(type) {
+	case model.Dictionary:
+		address = typed.Copy()
+	case nil:
+		address = model.NewDictionary()
+	default:
+		return address, fmt.Errorf("address mask needs an object, not %T", value)
+	}
+
+	town, changed := am.pickTown(address)
+	address.Set(am.fields.Number, 1+am.rand.Intn(MaxNumber))
+	address.Set(am.fields.Street, am.streets.Pick())
+	if changed {
+		address.Set(am.fields.Postcode, town.Postcode)
+		address.Set(am.fields.City, town.Name)
+	}
+	address.Set(am.fields.Country, am.country)
+	return address, nil
+}
+
+// pickTown returns a new town, or false if the original city and postcode are kept unchanged
+func (am MaskEngine) pickTown(address model.Dictionary) (maskingdata.Town, bool) {
+	switch am.keep {
+	case "city":
+		if city, ok := address.GetValue(am.fields.City); ok && city != nil {
+			return maskingdata.Town{}, false
+		}
+	case "region":
+		if postcode, ok := address.GetValue(am.fields.Postcode); ok && postcode != nil {
+			chooser, known := am.regions[Region(am.locale, fmt.Sprint(postcode))]
+			// without a town of the same region in the dataset, the original town is kept
+			if !known {
+				return maskingdata.Town{}, false
+			}
+			return chooser.Pick().(maskingdata.Town), true
+		}
+	}
+	return am.towns.Pick().(maskingdata.Town), true
+}
+
+// Factory create a mask from a configuration
+func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (model.MaskContextEngine, bool, error) {
+	if conf.Mask.Address != nil {
+		// set differents seeds for differents jsonpath
+		h := fnv.New64a()
+		h.Write([]byte(conf.Selector.Jsonpath))
+		seed += int64(h.Sum64())
+		mask, err := NewMask(*conf.Mask.Address, seed)
+		return mask, true, err
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,147 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package address
+
+import (
+	"testing"
+
+	"github.com/cgi-fr/pimo/pkg/maskingdata"
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func findTown(locale string, postcode model.Entry) (maskingdata.Town, bool) {
+	for _, town := range maskingdata.AddressData[locale].Towns {
+		if town.Postcode == postcode {
+			return town, true
+		}
+	}
+	return maskingdata.Town{}, false
+}
+
+func TestMaskingShouldWriteCoherentAddress(t *testing.T) {
+	mask, err := NewMask(model.AddressType{Locale: "DE"}, 42)
+	assert.NoError(t, err)
+	data := model.NewDictionary().With("address", model.NewDictionary().With("street", "Rue de Paris").With("other", 1))
+	for i := 0; i < 50; i++ {
+		result, err := mask.MaskContext(data.Copy(), "address", data)
+		assert.NoError(t, err)
+		address := result.Get("address").(model.Dictionary)
+		town, ok := findTown("DE", address.Get("postcode"))
+		assert.True(t, ok)
+		assert.Equal(t, town.Name, address.Get("city"))
+		assert.Equal(t, "Germany", address.Get("country"))
+		assert.Equal(t, 1, address.Get("other"))
+		assert.NotEqual(t, "Rue de Paris", address.Get("street"))
+		assert.GreaterOrEqual(t, address.Get("number"), 1)
+	}
+}
+
+func TestMaskingShouldUseFieldNames(t *testing.T) {
+	mask, err := NewMask(model.AddressType{Fields: model.AddressFieldsType{City: "ville", Postcode: "cp"}}, 42)
+	assert.NoError(t, err)
+	result, err := mask.MaskContext(model.NewDictionary(), "address")
+	assert.NoError(t, err)
+	address := result.Get("address").(model.Dictionary)
+	town, ok := findTown("FR", address.Get("cp"))
+	assert.True(t, ok)
+	assert.Equal(t, town.Name, address.Get("ville"))
+	assert.Equal(t, "France", address.Get("country"))
+	_, hasCity := address.GetValue("city")
+	assert.False(t, hasCity)
+}
+
+func TestMaskingShouldKeepCity(t *testing.T) {
+	mask, err := NewMask(model.AddressType{Keep: "city"}, 42)
+	assert.NoError(t, err)
+	data := model.NewDictionary().With("address", model.NewDictionary().With("city", "Valence").With("postcode", "26000").With("street", "Rue Émile Augier"))
+	result, err := mask.MaskContext(data, "address")
+	assert.NoError(t, err)
+	address := result.Get("address").(model.Dictionary)
+	assert.Equal(t, "Valence", address.Get("city"))
+	assert.Equal(t, "26000", address.Get("postcode"))
+	assert.NotEqual(t, "Rue Émile Augier", address.Get("street"))
+}
+
+func TestMaskingShouldKeepRegion(t *testing.T) {
+	mask, err := NewMask(model.AddressType{Keep: "region"}, 42)
+	assert.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		data := model.NewDictionary().With("address", model.NewDictionary().With("city", "Aubagne").With("postcode", "13400"))
+		result, err := mask.MaskContext(data, "address")
+		assert.NoError(t, err)
+		address := result.Get("address").(model.Dictionary)
+		assert.Equal(t, "13", address.Get("postcode").(string)[:2])
+		_, ok := findTown("FR", address.Get("postcode"))
+		assert.True(t, ok)
+	}
+
+	// unknown region keeps the original town
+	data := model.NewDictionary().With("address", model.NewDictionary().With("city", "Ajaccio").With("postcode", "20000"))
+	result, err := mask.MaskContext(data, "address")
+	assert.NoError(t, err)
+	address := result.Get("address").(model.Dictionary)
+	assert.Equal(t, "Ajaccio", address.Get("city"))
+	assert.Equal(t, "20000", address.Get("postcode"))
+}
+
+func TestMaskingShouldMaskArrays(t *testing.T) {
+	mask, err := NewMask(model.AddressType{Locale: "uk"}, 42)
+	assert.NoError(t, err)
+	data := model.NewDictionary().With("addresses", []model.Entry{model.NewDictionary(), nil})
+	result, err := mask.MaskContext(data, "addresses")
+	assert.NoError(t, err)
+	addresses := result.Get("addresses").([]model.Entry)
+	assert.Len(t, addresses, 2)
+	for _, a := range addresses {
+		assert.Equal(t, "United Kingdom", a.(model.Dictionary).Get("country"))
+	}
+
+	_, err = mask.MaskContext(model.NewDictionary().With("addresses", "1 High Street"), "addresses")
+	assert.Error(t, err)
+}
+
+func TestRegion(t *testing.T) {
+	assert.Equal(t, "SW", Region("UK", "SW1A 1AA"))
+	assert.Equal(t, "B", Region("UK", "B1 1AA"))
+	assert.Equal(t, "1", Region("BE", "1050"))
+	assert.Equal(t, "75", Region("FR", "75001"))
+}
+
+func TestMaskingShouldBeSeeded(t *testing.T) {
+	mask1, _ := NewMask(model.AddressType{}, 7)
+	mask2, _ := NewMask(model.AddressType{}, 7)
+	result1, _ := mask1.MaskContext(model.NewDictionary(), "address")
+	result2, _ := mask2.MaskContext(model.NewDictionary(), "address")
+	assert.Equal(t, result1, result2)
+}
+
+func TestFactoryShouldCreateAMask(t *testing.T) {
+	maskingConfig := model.Masking{Selector: model.SelectorType{Jsonpath: "address"}, Mask: model.MaskType{Address: &model.AddressType{Locale: "IT"}}}
+	_, present, err := Factory(maskingConfig, 0, nil)
+	assert.NoError(t, err)
+	assert.True(t, present)
+
+	maskingConfig.Mask.Address = &model.AddressType{Locale: "XX"}
+	_, _, err = Factory(maskingConfig, 0, nil)
+	assert.Error(t, err)
+
+	maskingConfig.Mask.Address = &model.AddressType{Keep: "street"}
+	_, _, err = Factory(maskingConfig, 0, nil)
+	assert.Error(t, err)
+}
@@ -19,6 +19,7 @@
 
 import (
 	"bytes"
+	"hash/fnv"
 
 	"github.com/cgi-fr/pimo/pkg/model"
 	"github.com/cgi-fr/pimo/pkg/template"
@@ -29,15 +30,17 @@
 type MaskEngine struct {
 	value    model.Entry
 	template *template.Engine
+	model.RandState
 }
 
-// NewMask return a MaskEngine from a value
-func NewMask(value model.Entry) (MaskEngine, error) {
+// NewMask return a MaskEngine from a value, random functions of a template value are seeded with the seed
+func NewMask(value model.Entry, seed int64, caches map[string]model.Cache) (MaskEngine, error) {
+	source := model.NewRandSource(seed)
 	if tmplstr, ok := value.(string); ok {
-		temp, err := template.NewEngine(tmplstr)
-		return MaskEngine{value, temp}, err
+		temp, err := model.NewTemplateEngine(tmplstr, source, caches)
+		return MaskEngine{value, temp, model.NewRandState(source)}, err
 	}
-	return MaskEngine{value, nil}, nil
+	return MaskEngine{value, nil, model.NewRandState(source)}, nil
 }
 
 // MaskContext add the field
@@ -74,7 +77,11 @@ func (am MaskEngine) GetCleaner() model.FunctionMaskContextEngine {
 // Create a mask from a configuration
 func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (model.MaskContextEngine, bool, error) {
 	if conf.Mask.AddTransient != nil {
-		mask, err := NewMask(conf.Mask.AddTransient)
+		// set differents seeds for differents jsonpath
+		h := fnv.New64a()
+		h.Write([]byte(conf.Selector.Jsonpath))
+		seed += int64(h.Sum64())
+		mask, err := NewMask(conf.Mask.AddTransient, seed, caches)
 		if err != nil {
 			return nil, false, err
 		}
@@ -0,0 +1,217 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package anonymity
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/cgi-fr/pimo/pkg/jsonline"
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// Suppressed replaces values of quasi-identifiers with the generalize action
+const Suppressed = "*"
+
+// Config of an anonymity check
+type Config struct {
+	QuasiIdentifiers []string
+	K                int
+	// Action applied to records in classes smaller than K : "" (report only), "suppress" or "generalize"
+	Action string
+	// TempDir is the directory of the file buffering records between the two passes, the default temporary directory if empty
+	TempDir string
+}
+
+// Report is the distribution of the sizes of equivalence classes (records sharing the same quasi-identifiers)
+type Report struct {
+	K                  int         `json:"k"`
+	Records            int         `json:"records"`
+	Classes            int         `json:"classes"`
+	MinClassSize       int         `json:"minClassSize"`
+	Distribution       map[int]int `json:"distribution"`
+	ViolatingClasses   int         `json:"violatingClasses"`
+	ViolatingRecords   int         `json:"violatingRecords"`
+	Anonymous          bool        `json:"anonymous"`
+	SuppressedRecords  int         `json:"suppressedRecords,omitempty"`
+	GeneralizedRecords int         `json:"generalizedRecords,omitempty"`
+	Output             *Report     `json:"output,omitempty"`
+}
+
+// Counter counts records of each equivalence class
+type Counter struct {
+	selectors []model.Selector
+	classes   map[string]int
+	records   int
+}
+
+// NewCounter create a Counter of classes over the quasi-identifiers
+func NewCounter(quasiIdentifiers []string) *Counter {
+	selectors := []model.Selector{}
+	for _, path := range quasiIdentifiers {
+		selectors = append(selectors, model.NewPathSelector(path))
+	}
+	return &Counter{selectors, map[string]int{}, 0}
+}
+
+// Class returns the key of the equivalence class of the dictionary, a missing quasi-identifier is null
+func (c *Counter) Class(dictionary model.Dictionary) (string, error) {
+	values := make([]model.Entry, len(c.selectors))
+	for i, selector := range c.selectors {
+		values[i], _ = selector.Read(dictionary)
+	}
+	key, err := json.Marshal(values)
+	return string(key), err
+}
+
+// Add counts the dictionary in its equivalence class
+func (c *Counter) Add(dictionary model.Dictionary) error {
+	class, err := c.Class(dictionary)
+	if err != nil {
+		return err
+	}
+	c.classes[class]++
+	c.records++
+	return nil
+}
+
+// Size returns the number of records in the equivalence class of the dictionary
+func (c *Counter) Size(dictionary model.Dictionary) (int, error) {
+	class, err := c.Class(dictionary)
+	return c.classes[class], err
+}
+
+// Report returns the distribution of class sizes, classes smaller than k are violations
+func (c *Counter) Report(k int) Report {
+	report := Report{K: k, Records: c.records, Classes: len(c.classes), Distribution: map[int]int{}}
+	for _, size := range c.classes {
+		report.Distribution[size]++
+		if report.MinClassSize == 0 || size < report.MinClassSize {
+			report.MinClassSize = size
+		}
+		if size < k {
+			report.ViolatingClasses++
+			report.ViolatingRecords += size
+		}
+	}
+	report.Anonymous = report.ViolatingClasses == 0
+	return report
+}
+
+// Run counts equivalence classes of the records from the source, then writes records to out with the action applied
+// to records in classes smaller than k. Records are buffered in a temporary file between the two passes.
+func Run(source model.Source, out io.Writer, conf Config) (Report, error) {
+	if len(conf.QuasiIdentifiers) == 0 {
+		return Report{}, fmt.Errorf("at least one quasi-identifier is needed")
+	}
+	if conf.K < 1 {
+		return Report{}, fmt.Errorf("k must be positive")
+	}
+	switch conf.Action {
+	case "", "suppress", "generalize":
+	default:
+		return Report{}, fmt.Errorf("action '%s' is not supported, use one of suppress, generalize", conf.Action)
+	}
+
+	counter := NewCounter(conf.QuasiIdentifiers)
+	var sink model.SinkProcess = jsonline.NewSink(ioutil.Discard)
+	var spill *os.File
+	if conf.Action != "" {
+		var err error
+		if spill, err = ioutil.TempFile(conf.TempDir, "pimo-anonymity-*.jsonl"); err != nil {
+			return Report{}, err
+		}
+		defer os.Remove(spill.Name())
+		defer spill.Close()
+		sink = jsonline.NewSink(spill)
+	}
+
+	log.Info().Strs("quasi-identifiers", conf.QuasiIdentifiers).Int("k", conf.K).Msg("Count equivalence classes")
+	err := model.NewPipeline(source).
+		Process(model.NewMapProcess(func(dictionary model.Dictionary) (model.Dictionary, error) {
+			return dictionary, counter.Add(dictionary)
+		})).
+		AddSink(sink).
+		Run()
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := counter.Report(conf.K)
+	if conf.Action == "" {
+		return report, nil
+	}
+
+	if _, err := spill.Seek(0, io.SeekStart); err != nil {
+		return report, err
+	}
+	log.Info().Str("action", conf.Action).Int("violating-records", report.ViolatingRecords).Msg("Apply action to records in small classes")
+	output := NewCounter(conf.QuasiIdentifiers)
+	err = model.NewPipeline(jsonline.NewSource(spill)).
+		Process(&actionProcess{counter, conf, &report}).
+		Process(model.NewMapProcess(func(dictionary model.Dictionary) (model.Dictionary, error) {
+			return dictionary, output.Add(dictionary)
+		})).
+		AddSink(jsonline.NewSink(out)).
+		Run()
+	if err != nil {
+		return report, err
+	}
+	outputReport := output.Report(conf.K)
+	report.Output = &outputReport
+	return report, nil
+}
+
+// actionProcess suppresses or generalizes records in classes smaller than k
+type actionProcess struct {
+	counter *Counter
+	conf    Config
+	report  *Report
+}
+
+func (p *actionProcess) Open() error {
+	return nil
+}
+
+func (p *actionProcess) ProcessDictionary(dictionary model.Dictionary, out model.Collector) error {
+	size, err := p.counter.Size(dictionary)
+	if err != nil {
+		return err
+	}
+	if size >= p.conf.K {
+		out.Collect(dictionary)
+		return nil
+	}
+	if p.conf.Action == "suppress" {
+		p.report.SuppressedRecords++
+		return nil
+	}
+	result := dictionary
+	for _, selector := range p.counter.selectors {
+		if _, found := selector.Read(result); found {
+			result = selector.Write(result, Suppressed)
+		}
+	}
+	p.report.GeneralizedRecords++
+	out.Collect(result)
+	return nil
+}
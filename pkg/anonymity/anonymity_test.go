@@ -0,0 +1,97 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package anonymity
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cgi-fr/pimo/pkg/jsonline"
+	"github.com/stretchr/testify/assert"
+)
+
+const records = `{"name":"a","zip":"44000","birth":{"year":1980}}
+{"name":"b","zip":"44000","birth":{"year":1980}}
+{"name":"c","zip":"44000","birth":{"year":1981}}
+{"name":"d","zip":"75001","birth":{"year":1990}}
+{"name":"e","zip":"75001","birth":{"year":1990}}
+{"name":"f","zip":"75001","birth":{"year":1990}}
+{"name":"g","zip":"35000"}
+`
+
+func run(t *testing.T, conf Config) (Report, string) {
+	var out bytes.Buffer
+	report, err := Run(jsonline.NewSource(strings.NewReader(records)), &out, conf)
+	assert.Nil(t, err)
+	return report, out.String()
+}
+
+func TestRunShouldReportDistribution(t *testing.T) {
+	report, out := run(t, Config{QuasiIdentifiers: []string{"zip", "birth.year"}, K: 2})
+
+	assert.Equal(t, "", out)
+	assert.Equal(t, 7, report.Records)
+	assert.Equal(t, 4, report.Classes)
+	assert.Equal(t, 1, report.MinClassSize)
+	assert.Equal(t, map[int]int{1: 2, 2: 1, 3: 1}, report.Distribution)
+	assert.Equal(t, 2, report.ViolatingClasses)
+	assert.Equal(t, 2, report.ViolatingRecords)
+	assert.False(t, report.Anonymous)
+	assert.Nil(t, report.Output)
+}
+
+func TestRunShouldSuppressRecordsInSmallClasses(t *testing.T) {
+	report, out := run(t, Config{QuasiIdentifiers: []string{"zip", "birth.year"}, K: 3, Action: "suppress"})
+
+	assert.Equal(t, `{"name":"d","zip":"75001","birth":{"year":1990}}
+{"name":"e","zip":"75001","birth":{"year":1990}}
+{"name":"f","zip":"75001","birth":{"year":1990}}
+`, out)
+	assert.Equal(t, 4, report.SuppressedRecords)
+	assert.True(t, report.Output.Anonymous)
+	assert.Equal(t, 3, report.Output.Records)
+}
+
+func TestRunShouldGeneralizeRecordsInSmallClasses(t *testing.T) {
+	report, out := run(t, Config{QuasiIdentifiers: []string{"zip", "birth.year"}, K: 2, Action: "generalize"})
+
+	assert.Equal(t, `{"name":"a","zip":"44000","birth":{"year":1980}}
+{"name":"b","zip":"44000","birth":{"year":1980}}
+{"name":"c","zip":"*","birth":{"year":"*"}}
+{"name":"d","zip":"75001","birth":{"year":1990}}
+{"name":"e","zip":"75001","birth":{"year":1990}}
+{"name":"f","zip":"75001","birth":{"year":1990}}
+{"name":"g","zip":"*"}
+`, out)
+	assert.Equal(t, 2, report.GeneralizedRecords)
+	// generalized records have different missing quasi-identifiers, so they stay in separate classes
+	assert.False(t, report.Output.Anonymous)
+	assert.Equal(t, 2, report.Output.ViolatingClasses)
+}
+
+func TestRunShouldRejectInvalidConfig(t *testing.T) {
+	for _, conf := range []Config{
+		{K: 2},
+		{QuasiIdentifiers: []string{"zip"}, K: 0},
+		{QuasiIdentifiers: []string{"zip"}, K: 2, Action: "drop"},
+	} {
+		_, err := Run(jsonline.NewSource(strings.NewReader(records)), &bytes.Buffer{}, conf)
+		assert.NotNil(t, err, "%+v", conf)
+	}
+}
@@ -0,0 +1,319 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package cachedump reads, inspects and transforms the dumps of caches written by the --dump-cache flag
+package cachedump
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"regexp"
+	"strings"
+
+	"github.com/cgi-fr/pimo/pkg/jsonline"
+	"github.com/cgi-fr/pimo/pkg/model"
+)
+
+// Formats of dumps
+const (
+	JSONLine = "jsonline"
+	CSV      = "csv"
+)
+
+// Policies to merge dumps when a key has different values
+const (
+	KeepFirst  = "first"
+	KeepSecond = "second"
+	Fail       = "error"
+)
+
+// Entry is a key and its value in a cache dump
+type Entry struct {
+	Key   model.Entry `json:"key"`
+	Value model.Entry `json:"value"`
+}
+
+// Duplicate is a value shared by several keys of a dump
+type Duplicate struct {
+	Value model.Entry   `json:"value"`
+	Keys  []model.Entry `json:"keys"`
+}
+
+// Report describes the content of a dump
+type Report struct {
+	Entries          int         `json:"entries"`
+	DistinctKeys     int         `json:"distinctKeys"`
+	DistinctValues   int         `json:"distinctValues"`
+	DuplicatedValues []Duplicate `json:"duplicatedValues"`
+	Sample           []Entry     `json:"sample"`
+}
+
+// FormatOf returns the format of a dump from the extension of its path
+func FormatOf(path string) string {
+	if strings.HasSuffix(strings.ToLower(path), ".csv") {
+		return CSV
+	}
+	return JSONLine
+}
+
+// Read reads the entries of a dump in the given format, CSV dumps have a key,value header and cells are JSON values
+// or strings if they are not valid JSON
+func Read(reader io.Reader, format string) ([]Entry, error) {
+	switch format {
+	case JSONLine:
+		dictionaries := []model.Dictionary{}
+		if err := model.NewPipeline(jsonline.NewSource(reader)).AddSink(model.NewSinkToSlice(&dictionaries)).Run(); err != nil {
+			return nil, err
+		}
+		entries := make([]Entry, 0, len(dictionaries))
+		for _, d := range dictionaries {
+			key, ok := d.GetValue("key")
+			if !ok {
+				return nil, fmt.Errorf("entry without key: %s", d)
+			}
+			entries = append(entries, Entry{key, d.Get("value")})
+		}
+		return entries, nil
+	case CSV:
+		records, err := csv.NewReader(reader).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(records) > 0 && len(records[0]) == 2 && records[0][0] == "key" && records[0][1] == "value" {
+			records = records[1:]
+		}
+		entries := make([]Entry, 0, len(records))
+		for _, record := range records {
+			if len(record) != 2 {
+				return nil, fmt.Errorf("CSV record %v must have a key and a value", record)
+			}
+			key, err := fromCell(record[0])
+			if err != nil {
+				return nil, err
+			}
+			value, err := fromCell(record[1])
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, Entry{key, value})
+		}
+		return entries, nil
+	default:
+		return nil, fmt.Errorf("unknown format '%s', must be %s or %s", format, JSONLine, CSV)
+	}
+}
+
+// Write writes the entries of a dump in the given format, values are written as JSON in CSV dumps
+// except strings that are not valid JSON, so types of values are kept
+func Write(writer io.Writer, format string, entries []Entry) error {
+	switch format {
+	case JSONLine:
+		dictionaries := make([]model.Dictionary, 0, len(entries))
+		for _, entry := range entries {
+			dictionaries = append(dictionaries, model.NewDictionary().With("key", entry.Key).With("value", entry.Value))
+		}
+		return model.NewPipelineFromSlice(dictionaries).AddSink(jsonline.NewSink(writer)).Run()
+	case CSV:
+		w := csv.NewWriter(writer)
+		if err := w.Write([]string{"key", "value"}); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			key, err := toCell(entry.Key)
+			if err != nil {
+				return err
+			}
+			value, err := toCell(entry.Value)
+			if err != nil {
+				return err
+			}
+			if err := w.Write([]string{key, value}); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		return w.Error()
+	default:
+		return fmt.Errorf("unknown format '%s', must be %s or %s", format, JSONLine, CSV)
+	}
+}
+
+// Inspect counts the keys and values of a dump, lists values shared by several keys and picks a random sample of entries
+func Inspect(entries []Entry, sampleSize int, seed int64) (Report, error) {
+	report := Report{Entries: len(entries), DuplicatedValues: []Duplicate{}, Sample: []Entry{}}
+
+	keys := map[string]struct{}{}
+	values := map[string]*Duplicate{}
+	order := []string{}
+	for _, entry := range entries {
+		key, err := identity(entry.Key)
+		if err != nil {
+			return report, err
+		}
+		keys[key] = struct{}{}
+
+		value, err := identity(entry.Value)
+		if err != nil {
+			return report, err
+		}
+		if duplicate, ok := values[value]; ok {
+			duplicate.Keys = append(duplicate.Keys, entry.Key)
+		} else {
+			values[value] = &Duplicate{entry.Value, []model.Entry{entry.Key}}
+			order = append(order, value)
+		}
+	}
+	report.DistinctKeys = len(keys)
+	report.DistinctValues = len(values)
+	for _, value := range order {
+		if duplicate := values[value]; len(duplicate.Keys) > 1 {
+			report.DuplicatedValues = append(report.DuplicatedValues, *duplicate)
+		}
+	}
+
+	// nolint: gosec
+	r := rand.New(rand.NewSource(seed))
+	for i, entry := range entries {
+		switch {
+		case i < sampleSize:
+			report.Sample = append(report.Sample, entry)
+		default:
+			if j := r.Intn(i + 1); j < sampleSize {
+				report.Sample[j] = entry
+			}
+		}
+	}
+	return report, nil
+}
+
+// Merge returns the entries of both dumps, the policy decides which value is kept when a key has different values
+func Merge(first, second []Entry, policy string) ([]Entry, error) {
+	if policy != KeepFirst && policy != KeepSecond && policy != Fail {
+		return nil, fmt.Errorf("unknown conflict policy '%s', must be %s, %s or %s", policy, KeepFirst, KeepSecond, Fail)
+	}
+
+	result := make([]Entry, 0, len(first)+len(second))
+	index := map[string]int{}
+	for _, entry := range append(append([]Entry{}, first...), second...) {
+		key, err := identity(entry.Key)
+		if err != nil {
+			return nil, err
+		}
+		i, exists := index[key]
+		if !exists {
+			index[key] = len(result)
+			result = append(result, entry)
+			continue
+		}
+		same, err := equal(result[i].Value, entry.Value)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case same, policy == KeepFirst:
+			// the value of the first dump is kept
+		case policy == KeepSecond:
+			result[i] = entry
+		default:
+			return nil, fmt.Errorf("key %s has different values %s and %s", key, mustIdentity(result[i].Value), mustIdentity(entry.Value))
+		}
+	}
+	return result, nil
+}
+
+// Invert swaps keys and values, an error is returned if a value is shared by several keys
+func Invert(entries []Entry) ([]Entry, error) {
+	result := make([]Entry, 0, len(entries))
+	values := map[string]struct{}{}
+	for _, entry := range entries {
+		value, err := identity(entry.Value)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := values[value]; ok {
+			return nil, fmt.Errorf("value %s is shared by several keys, the dump can't be inverted", value)
+		}
+		values[value] = struct{}{}
+		result = append(result, Entry{entry.Value, entry.Key})
+	}
+	return result, nil
+}
+
+// Filter keeps the entries with a key matching the pattern, non string keys are matched on their JSON form
+func Filter(entries []Entry, pattern *regexp.Regexp) ([]Entry, error) {
+	result := []Entry{}
+	for _, entry := range entries {
+		key, err := toText(entry.Key)
+		if err != nil {
+			return nil, err
+		}
+		if pattern.MatchString(key) {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+// identity returns the JSON form of a key or a value, so that "1" and 1 are different
+func identity(e model.Entry) (string, error) {
+	b, err := json.Marshal(e)
+	return string(b), err
+}
+
+func mustIdentity(e model.Entry) string {
+	id, _ := identity(e)
+	return id
+}
+
+func equal(a, b model.Entry) (bool, error) {
+	idA, err := identity(a)
+	if err != nil {
+		return false, err
+	}
+	idB, err := identity(b)
+	return idA == idB, err
+}
+
+func toText(e model.Entry) (string, error) {
+	if s, ok := e.(string); ok {
+		return s, nil
+	}
+	return identity(e)
+}
+
+// toCell writes a string as is if it can't be read as JSON (e.g. john but not 3 or true), other values as JSON
+func toCell(e model.Entry) (string, error) {
+	if s, ok := e.(string); ok && !json.Valid([]byte(s)) {
+		return s, nil
+	}
+	return identity(e)
+}
+
+// fromCell reads a cell written by toCell, numbers and objects are read like in jsonline dumps
+func fromCell(cell string) (model.Entry, error) {
+	if !json.Valid([]byte(cell)) {
+		return cell, nil
+	}
+	dict, err := jsonline.JSONToDictionary([]byte(`{"cell":` + cell + `}`))
+	if err != nil {
+		return nil, err
+	}
+	return dict.Get("cell"), nil
+}
@@ -0,0 +1,140 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package cachedump
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const dump = `{"key":"alice","value":"X1"}
+{"key":"bob","value":"X2"}
+{"key":"carol","value":"X1"}
+{"key":3,"value":4}
+`
+
+func read(t *testing.T, content string, format string) []Entry {
+	entries, err := Read(strings.NewReader(content), format)
+	assert.Nil(t, err)
+	return entries
+}
+
+func write(t *testing.T, entries []Entry, format string) string {
+	var output bytes.Buffer
+	assert.Nil(t, Write(&output, format, entries))
+	return output.String()
+}
+
+func TestReadWriteJSONLine(t *testing.T) {
+	entries := read(t, dump, JSONLine)
+
+	assert.Equal(t, []Entry{{"alice", "X1"}, {"bob", "X2"}, {"carol", "X1"}, {json.Number("3"), json.Number("4")}}, entries)
+	assert.Equal(t, dump, write(t, entries, JSONLine))
+
+	_, err := Read(strings.NewReader(`{"value":"X1"}`), JSONLine)
+	assert.NotNil(t, err)
+}
+
+func TestReadWriteCSV(t *testing.T) {
+	content := write(t, read(t, dump, JSONLine), CSV)
+
+	assert.Equal(t, "key,value\nalice,X1\nbob,X2\ncarol,X1\n3,4\n", content)
+	assert.Equal(t, []Entry{{"alice", "X1"}, {"bob", "X2"}, {"carol", "X1"}, {json.Number("3"), json.Number("4")}}, read(t, content, CSV))
+	assert.Equal(t, []Entry{{"a", "b"}}, read(t, "a,b\n", CSV))
+
+	_, err := Read(strings.NewReader("a,b,c\n"), CSV)
+	assert.NotNil(t, err)
+	_, err = Read(strings.NewReader(""), "xml")
+	assert.NotNil(t, err)
+}
+
+func TestCSVShouldKeepTypes(t *testing.T) {
+	content := `{"key":3,"value":4}
+{"key":"3","value":"true"}
+{"key":"john","value":{"name":"X1","tags":["a","b"]}}
+{"key":"","value":null}
+{"key":"\"quoted\"","value":" spaced "}
+`
+	entries := read(t, content, JSONLine)
+	csvContent := write(t, entries, CSV)
+	assert.Equal(t, entries, read(t, csvContent, CSV))
+	assert.Equal(t, content, write(t, read(t, csvContent, CSV), JSONLine))
+}
+
+func TestFormatOf(t *testing.T) {
+	assert.Equal(t, CSV, FormatOf("cache.CSV"))
+	assert.Equal(t, JSONLine, FormatOf("cache.jsonl"))
+}
+
+func TestInspect(t *testing.T) {
+	report, err := Inspect(read(t, dump, JSONLine), 2, 42)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 4, report.Entries)
+	assert.Equal(t, 4, report.DistinctKeys)
+	assert.Equal(t, 3, report.DistinctValues)
+	assert.Equal(t, []Duplicate{{"X1", []model.Entry{"alice", "carol"}}}, report.DuplicatedValues)
+	assert.Len(t, report.Sample, 2)
+
+	again, _ := Inspect(read(t, dump, JSONLine), 2, 42)
+	assert.Equal(t, report.Sample, again.Sample)
+}
+
+func TestMerge(t *testing.T) {
+	first := read(t, dump, JSONLine)
+	second := []Entry{{"bob", "Y2"}, {"dave", "X4"}, {"alice", "X1"}}
+
+	_, err := Merge(first, second, Fail)
+	assert.NotNil(t, err)
+
+	merged, err := Merge(first, second, KeepFirst)
+	assert.Nil(t, err)
+	assert.Equal(t, append(first, Entry{"dave", "X4"}), merged)
+
+	merged, err = Merge(first, second, KeepSecond)
+	assert.Nil(t, err)
+	assert.Equal(t, Entry{"bob", "Y2"}, merged[1])
+
+	merged, err = Merge(first, []Entry{{"alice", "X1"}}, Fail)
+	assert.Nil(t, err)
+	assert.Equal(t, first, merged)
+
+	_, err = Merge(first, second, "unknown")
+	assert.NotNil(t, err)
+}
+
+func TestInvert(t *testing.T) {
+	inverted, err := Invert([]Entry{{"alice", "X1"}, {"bob", "X2"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []Entry{{"X1", "alice"}, {"X2", "bob"}}, inverted)
+
+	_, err = Invert(read(t, dump, JSONLine))
+	assert.NotNil(t, err)
+}
+
+func TestFilter(t *testing.T) {
+	filtered, err := Filter(read(t, dump, JSONLine), regexp.MustCompile("^(a|3)"))
+	assert.Nil(t, err)
+	assert.Equal(t, []Entry{{"alice", "X1"}, {json.Number("3"), json.Number("4")}}, filtered)
+}
@@ -0,0 +1,142 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package cachedump
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	b64 "encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// header starts the encrypted dumps, it is authenticated with the content
+var header = []byte("PIMO-AES-GCM-1\n")
+
+// ErrKeyRequired is returned when an encrypted dump is read without a key
+var ErrKeyRequired = errors.New("dump is encrypted, a key is required to read it")
+
+// LoadKey reads a base64 AES key (16, 24 or 32 bytes) from an environment variable or a file, nil if none is given
+func LoadKey(fromEnv, fromFile string) ([]byte, error) {
+	var encoded string
+	switch {
+	case fromEnv != "" && fromFile != "":
+		return nil, fmt.Errorf("key must be read either from an environment variable or from a file")
+	case fromEnv != "":
+		encoded = os.Getenv(fromEnv)
+		if encoded == "" {
+			return nil, fmt.Errorf("Environment variable named '%s' should be defined", fromEnv)
+		}
+	case fromFile != "":
+		content, err := ioutil.ReadFile(fromFile)
+		if err != nil {
+			return nil, err
+		}
+		encoded = strings.TrimSpace(string(content))
+	default:
+		return nil, nil
+	}
+
+	key, err := b64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("key is not encoded in base64: %s", err.Error())
+	}
+	if _, err := aes.NewCipher(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// IsEncrypted returns true if the dump was encrypted by Encrypt
+func IsEncrypted(content []byte) bool {
+	return bytes.HasPrefix(content, header)
+}
+
+// Encrypt encrypts a dump with AES-GCM, the result starts with a header used to detect encrypted dumps
+func Encrypt(content, key []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	result := append(append([]byte{}, header...), nonce...)
+	return aead.Seal(result, nonce, content, header), nil
+}
+
+// Decrypt decrypts a dump encrypted by Encrypt, a plain dump is returned unchanged
+func Decrypt(content, key []byte) ([]byte, error) {
+	if !IsEncrypted(content) {
+		return content, nil
+	}
+	if key == nil {
+		return nil, ErrKeyRequired
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	sealed := content[len(header):]
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted dump is truncated")
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], header)
+	if err != nil {
+		return nil, fmt.Errorf("can't decrypt dump, the key is wrong or the dump is corrupted")
+	}
+	return plain, nil
+}
+
+// NewReader returns a reader of the plain content of a dump, a plain dump is streamed,
+// an encrypted dump is read entirely to be decrypted
+func NewReader(reader io.Reader, key []byte) (io.Reader, error) {
+	buffered := bufio.NewReader(reader)
+	prefix, err := buffered.Peek(len(header))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if !IsEncrypted(prefix) {
+		return buffered, nil
+	}
+	content, err := ioutil.ReadAll(buffered)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := Decrypt(content, key)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(plain), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
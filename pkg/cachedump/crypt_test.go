@@ -0,0 +1,107 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package cachedump
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testKey = "70NZ2NWAqk9/A21vBPxqlA=="
+
+func TestLoadKey(t *testing.T) {
+	key, err := LoadKey("", "")
+	assert.Nil(t, err)
+	assert.Nil(t, key)
+
+	os.Setenv("PIMO_TEST_CACHE_KEY", testKey)
+	defer os.Unsetenv("PIMO_TEST_CACHE_KEY")
+	key, err = LoadKey("PIMO_TEST_CACHE_KEY", "")
+	assert.Nil(t, err)
+	assert.Len(t, key, 16)
+
+	file := filepath.Join(t.TempDir(), "key")
+	assert.Nil(t, ioutil.WriteFile(file, []byte(testKey+"\n"), 0o600))
+	fromFile, err := LoadKey("", file)
+	assert.Nil(t, err)
+	assert.Equal(t, key, fromFile)
+
+	_, err = LoadKey("PIMO_TEST_CACHE_KEY", file)
+	assert.NotNil(t, err)
+	_, err = LoadKey("PIMO_TEST_UNDEFINED_KEY", "")
+	assert.NotNil(t, err)
+
+	os.Setenv("PIMO_TEST_CACHE_KEY", "c2hvcnQ=")
+	_, err = LoadKey("PIMO_TEST_CACHE_KEY", "")
+	assert.NotNil(t, err)
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	content := []byte(`{"key":"alice","value":"X1"}` + "\n")
+
+	encrypted, err := Encrypt(content, key)
+	assert.Nil(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	assert.NotContains(t, string(encrypted), "alice")
+
+	decrypted, err := Decrypt(encrypted, key)
+	assert.Nil(t, err)
+	assert.Equal(t, content, decrypted)
+
+	plain, err := Decrypt(content, key)
+	assert.Nil(t, err)
+	assert.Equal(t, content, plain)
+
+	_, err = Decrypt(encrypted, nil)
+	assert.Equal(t, ErrKeyRequired, err)
+
+	_, err = Decrypt(encrypted, []byte("fedcba9876543210fedcba9876543210"))
+	assert.NotNil(t, err)
+
+	encrypted[len(encrypted)-1] ^= 1
+	_, err = Decrypt(encrypted, key)
+	assert.NotNil(t, err)
+}
+
+func TestNewReaderShouldDetectEncryptedDumps(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	content := []byte(`{"key":"alice","value":"X1"}` + "\n")
+	encrypted, err := Encrypt(content, key)
+	assert.Nil(t, err)
+
+	for _, dump := range [][]byte{content, encrypted, {}} {
+		reader, err := NewReader(bytes.NewReader(dump), key)
+		assert.Nil(t, err)
+		read, err := ioutil.ReadAll(reader)
+		assert.Nil(t, err)
+		if len(dump) == 0 {
+			assert.Empty(t, read)
+		} else {
+			assert.Equal(t, content, read)
+		}
+	}
+
+	_, err = NewReader(bytes.NewReader(encrypted), nil)
+	assert.Equal(t, ErrKeyRequired, err)
+}
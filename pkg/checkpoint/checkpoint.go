@@ -0,0 +1,237 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/cgi-fr/pimo/pkg/cachedump"
+	"github.com/cgi-fr/pimo/pkg/jsonline"
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// Checkpoint is the state of a run after a number of input lines have been fully processed
+type Checkpoint struct {
+	InputLine  int                          `json:"input-line"`
+	OutputLine int                          `json:"output-line"`
+	Masks      map[string]json.RawMessage   `json:"masks,omitempty"`
+	Caches     map[string][]json.RawMessage `json:"caches,omitempty"`
+}
+
+// Load reads a checkpoint from a file, an encrypted checkpoint is decrypted with the key
+func Load(path string, key []byte) (Checkpoint, error) {
+	var checkpoint Checkpoint
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return checkpoint, err
+	}
+	if content, err = cachedump.Decrypt(content, key); err != nil {
+		return checkpoint, err
+	}
+	err = json.Unmarshal(content, &checkpoint)
+	return checkpoint, err
+}
+
+// Save writes a checkpoint to a file, encrypted if a key is given because it contains the content of caches.
+// The previous checkpoint is replaced only when the new one is complete
+func Save(path string, checkpoint Checkpoint, key []byte) error {
+	content, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	if key != nil {
+		if content, err = cachedump.Encrypt(content, key); err != nil {
+			return err
+		}
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, content, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Restore sets the state of masks and the content of caches from the checkpoint
+func (c Checkpoint) Restore(masks map[string]model.Stateful, caches map[string]model.Cache) error {
+	for name, state := range c.Masks {
+		mask, ok := masks[name]
+		if !ok {
+			return fmt.Errorf("Mask %s from checkpoint not found in masking configuration", name)
+		}
+		if err := mask.Restore(state); err != nil {
+			return fmt.Errorf("Mask %s not restored : %s", name, err.Error())
+		}
+	}
+	for name, entries := range c.Caches {
+		cache, ok := caches[name]
+		if !ok {
+			return fmt.Errorf("Cache %s from checkpoint not found in masking configuration", name)
+		}
+		for _, entry := range entries {
+			dict, err := jsonline.JSONToDictionary(entry)
+			if err != nil {
+				return fmt.Errorf("Cache %s not restored : %s", name, err.Error())
+			}
+			if unique, ok := cache.(model.UniqueCache); ok {
+				unique.PutUnique(dict.Get("key"), dict.Get("value"))
+			} else {
+				cache.Put(dict.Get("key"), dict.Get("value"))
+			}
+		}
+	}
+	return nil
+}
+
+// Recorder saves the state of a run every interval lines read from the input
+type Recorder struct {
+	path     string
+	interval int
+	masks    map[string]model.Stateful
+	caches   map[string]model.Cache
+	written  int
+	key      []byte
+}
+
+// NewRecorder create a Recorder, checkpoints are encrypted with the key if it is not nil
+func NewRecorder(path string, interval int, key []byte) *Recorder {
+	return &Recorder{path, interval, map[string]model.Stateful{}, map[string]model.Cache{}, 0, key}
+}
+
+// Watch declares the masks and caches to save in checkpoints
+func (r *Recorder) Watch(masks map[string]model.Stateful, caches map[string]model.Cache) {
+	r.masks = masks
+	r.caches = caches
+}
+
+// Snapshot captures the state of masks and caches
+func (r *Recorder) Snapshot(inputLine int) (Checkpoint, error) {
+	checkpoint := Checkpoint{inputLine, r.written, map[string]json.RawMessage{}, map[string][]json.RawMessage{}}
+	for name, mask := range r.masks {
+		state, err := mask.State()
+		if err != nil {
+			return checkpoint, fmt.Errorf("Mask %s state not saved : %s", name, err.Error())
+		}
+		checkpoint.Masks[name] = state
+	}
+	for name, cache := range r.caches {
+		entries := []json.RawMessage{}
+		iterator := cache.Iterate()
+		for iterator.Next() {
+			entry, err := json.Marshal(iterator.Value())
+			if err != nil {
+				return checkpoint, fmt.Errorf("Cache %s not saved : %s", name, err.Error())
+			}
+			entries = append(entries, entry)
+		}
+		checkpoint.Caches[name] = entries
+	}
+	return checkpoint, nil
+}
+
+// Save writes the state of the run after inputLine lines
+func (r *Recorder) Save(inputLine int) error {
+	checkpoint, err := r.Snapshot(inputLine)
+	if err != nil {
+		return err
+	}
+	log.Debug().Int("input-line", inputLine).Int("output-line", r.written).Str("checkpoint", r.path).Msg("Save checkpoint")
+	return Save(r.path, checkpoint, r.key)
+}
+
+// Source wraps the input of the pipeline, offset is the number of lines already processed by a previous run.
+// A checkpoint is saved before reading a new line, at this time every previous line went through the whole pipeline.
+func (r *Recorder) Source(source model.Source, offset int) model.Source {
+	return &recordingSource{source, r, offset, offset, nil}
+}
+
+type recordingSource struct {
+	model.Source
+	recorder *Recorder
+	count    int
+	saved    int
+	err      error
+}
+
+func (s *recordingSource) Next() bool {
+	if s.recorder.interval > 0 && s.count-s.saved >= s.recorder.interval {
+		if s.err = s.recorder.Save(s.count); s.err != nil {
+			return false
+		}
+		s.saved = s.count
+	}
+	if !s.Source.Next() {
+		if s.Source.Err() == nil && s.count != s.saved {
+			s.err = s.recorder.Save(s.count)
+			s.saved = s.count
+		}
+		return false
+	}
+	s.count++
+	return true
+}
+
+func (s *recordingSource) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.Source.Err()
+}
+
+// Sink wraps the output of the pipeline to count written lines, offset is the number of lines written by a previous run.
+// Lines written after the last checkpoint are written again by a resumed run, the output must be truncated to the
+// output-line of the checkpoint before resuming.
+func (r *Recorder) Sink(sink model.SinkProcess, offset int) model.SinkProcess {
+	r.written = offset
+	return countingSink{sink, r}
+}
+
+type countingSink struct {
+	model.SinkProcess
+	recorder *Recorder
+}
+
+func (s countingSink) ProcessDictionary(dictionary model.Dictionary) error {
+	if err := s.SinkProcess.ProcessDictionary(dictionary); err != nil {
+		return err
+	}
+	s.recorder.written++
+	return nil
+}
+
+// Skip wraps a source to ignore the first lines, already processed by a previous run
+func Skip(source model.Source, lines int) model.Source {
+	return &skippingSource{source, lines}
+}
+
+type skippingSource struct {
+	model.Source
+	lines int
+}
+
+func (s *skippingSource) Next() bool {
+	for ; s.lines > 0; s.lines-- {
+		if !s.Source.Next() {
+			return false
+		}
+	}
+	return s.Source.Next()
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package checkpoint
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/cgi-fr/pimo/pkg/increment"
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func lines(n int) []model.Dictionary {
+	result := []model.Dictionary{}
+	for i := 0; i < n; i++ {
+		result = append(result, model.NewDictionary().With("id", i))
+	}
+	return result
+}
+
+func TestRecorderShouldSaveAndRestoreState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	mask := increment.NewMask(1, 1)
+	cache := model.NewMemCache()
+	recorder := NewRecorder(path, 2, nil)
+	recorder.Watch(map[string]model.Stateful{"0:id": mask}, map[string]model.Cache{"ids": cache})
+
+	var result []model.Dictionary
+	err := model.NewPipeline(recorder.Source(model.NewSourceFromSlice(lines(3)), 0)).
+		Process(model.NewMaskEngineProcess(model.NewPathSelector("id"), model.NewMaskCacheEngine(cache, mask), "")).
+		AddSink(recorder.Sink(model.NewSinkToSlice(&result), 0)).
+		Run()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(result))
+
+	saved, err := Load(path, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, saved.InputLine)
+	assert.Equal(t, 3, saved.OutputLine)
+	assert.Equal(t, 3, len(saved.Caches["ids"]))
+
+	restoredMask := increment.NewMask(1, 1)
+	restoredCache := model.NewMemCache()
+	err = saved.Restore(map[string]model.Stateful{"0:id": restoredMask}, map[string]model.Cache{"ids": restoredCache})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, *restoredMask.Value)
+	assert.Equal(t, 3, restoredCache.Len())
+}
+
+func TestRestoreShouldFailOnUnknownMask(t *testing.T) {
+	saved := Checkpoint{InputLine: 1, Masks: map[string]json.RawMessage{"0:id": json.RawMessage("1")}}
+	err := saved.Restore(map[string]model.Stateful{}, map[string]model.Cache{})
+	assert.NotNil(t, err)
+}
+
+func TestSkipShouldIgnoreFirstLines(t *testing.T) {
+	var result []model.Dictionary
+	err := model.NewPipeline(Skip(model.NewSourceFromSlice(lines(5)), 3)).
+		AddSink(model.NewSinkToSlice(&result)).
+		Run()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(result))
+	assert.Equal(t, 3, result[0].Get("id"))
+}
+
+func TestSaveShouldEncryptWithKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	key := []byte("0123456789abcdef0123456789abcdef")
+	saved := Checkpoint{InputLine: 2, OutputLine: 2, Caches: map[string][]json.RawMessage{"ids": {json.RawMessage(`{"key":"alice","value":1}`)}}}
+
+	assert.Nil(t, Save(path, saved, key))
+	content, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(content), "alice")
+
+	_, err = Load(path, nil)
+	assert.NotNil(t, err)
+	loaded, err := Load(path, key)
+	assert.Nil(t, err)
+	assert.Equal(t, saved, loaded)
+}
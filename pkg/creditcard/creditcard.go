@@ -0,0 +1,235 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package creditcard
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/cgi-fr/pimo/pkg/luhn"
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	PAN    = "pan"
+	Expiry = "expiry"
+	CVV    = "cvv"
+
+	// DefaultExpiryFormat is the layout of expiry dates, as a go time layout (MM/YY)
+	DefaultExpiryFormat = "01/06"
+
+	// DefaultReferenceDate is the date after which expiry dates are generated, the current date is not used
+	// so the same card always gets the same expiry date
+	DefaultReferenceDate = "2026-01-01"
+
+	referenceDateFormat = "2006-01-02"
+)
+
+// nolint: gochecknoglobals
+var checksum = luhn.NewMask([]byte("0123456789"))
+
+// MaskEngine is a mask to generate payment card numbers, expiry dates or CVV
+type MaskEngine struct {
+	rand *rand.Rand
+	model.RandState
+	seed         int64
+	networks     []network
+	keepBIN      int
+	keepLast4    bool
+	spaces       bool
+	generate     string
+	panField     string
+	expiryFormat string
+	reference    time.Time
+}
+
+// NewMask create a MaskEngine from a configuration
+func NewMask(conf model.CreditCardType, seed int64) (MaskEngine, error) {
+	selected := []network{}
+	for _, name := range conf.Networks {
+		n, ok := networkByName(strings.ToLower(name))
+		if !ok {
+			return MaskEngine{}, fmt.Errorf("network '%s' is not supported", name)
+		}
+		selected = append(selected, n)
+	}
+	if len(selected) == 0 {
+		selected = networks
+	}
+	if conf.KeepBIN != 0 && (conf.KeepBIN < 6 || conf.KeepBIN > 8) {
+		return MaskEngine{}, fmt.Errorf("keepBIN must be 6, 7 or 8")
+	}
+	generate := conf.Generate
+	switch generate {
+	case "":
+		generate = PAN
+	case PAN, Expiry, CVV:
+	default:
+		return MaskEngine{}, fmt.Errorf("generate '%s' is not supported, use %s, %s or %s", generate, PAN, Expiry, CVV)
+	}
+	expiryFormat := conf.ExpiryFormat
+	if expiryFormat == "" {
+		expiryFormat = DefaultExpiryFormat
+	}
+	referenceDate := conf.ReferenceDate
+	if referenceDate == "" {
+		referenceDate = DefaultReferenceDate
+	}
+	reference, err := time.Parse(referenceDateFormat, referenceDate)
+	if err != nil {
+		return MaskEngine{}, fmt.Errorf("referenceDate '%s' is not a date (YYYY-MM-DD)", conf.ReferenceDate)
+	}
+	source := model.NewRandSource(seed)
+	// nolint: gosec
+	return MaskEngine{rand.New(source), model.NewRandState(source), seed, selected, conf.KeepBIN, conf.KeepLast4, conf.Spaces, generate, conf.PANField, expiryFormat, reference}, nil
+}
+
+// Mask returns a new card number, expiry date or CVV
+func (me MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.Entry, error) {
+	log.Info().Msg("Mask creditCard")
+
+	switch me.generate {
+	case Expiry, CVV:
+		r, pan := me.rand, ""
+		if me.panField != "" && len(context) > 0 {
+			if value, ok := context[0].GetValue(me.panField); ok && value != nil {
+				// the same card always gets the same values
+				pan = digits(fmt.Sprint(value))
+				h := fnv.New64a()
+				h.Write([]byte(pan))
+				// nolint: gosec
+				r = rand.New(rand.NewSource(me.seed + int64(h.Sum64())))
+			}
+		}
+		if me.generate == Expiry {
+			expiry := time.Date(me.reference.Year()+1+r.Intn(4), time.Month(1+r.Intn(12)), 1, 0, 0, 0, 0, time.UTC)
+			return expiry.Format(me.expiryFormat), nil
+		}
+		length := 3
+		if n, ok := detect(pan); ok {
+			length = n.cvv
+		}
+		return randomDigits(r, length), nil
+	}
+
+	original := ""
+	if value, ok := e.(string); ok {
+		original = digits(value)
+	}
+	return me.pan(original)
+}
+
+func (me MaskEngine) pan(original string) (string, error) {
+	n := me.networks[me.rand.Intn(len(me.networks))]
+	length := n.length
+	prefix := ""
+	if me.keepBIN > 0 && len(original) >= 12 {
+		prefix = original[:me.keepBIN]
+		length = len(original)
+		if originalNetwork, ok := detect(original); ok {
+			n = originalNetwork
+		}
+	} else {
+		r := n.ranges[me.rand.Intn(len(n.ranges))]
+		prefix = fmt.Sprint(r.low + me.rand.Intn(r.high-r.low+1))
+	}
+
+	suffix := ""
+	if me.keepLast4 && len(original) >= 12 {
+		suffix = original[len(original)-4:]
+	}
+
+	number := []byte(prefix + randomDigits(me.rand, length-len(prefix)-len(suffix)) + suffix)
+	// the check digit is the last digit, or the digit before the last four if they are kept
+	check := length - 1 - len(suffix)
+	if check < len(prefix) {
+		return "", fmt.Errorf("card number is too short to keep BIN and last 4 digits")
+	}
+	for d := byte('0'); d <= '9'; d++ {
+		number[check] = d
+		if valid(string(number)) {
+			break
+		}
+	}
+
+	if !me.spaces {
+		return string(number), nil
+	}
+	return format(string(number), n.groups), nil
+}
+
+// valid returns true if the number has a valid Luhn check digit
+func valid(number string) bool {
+	result, err := checksum.Mask(number[:len(number)-1])
+	return err == nil && result == number
+}
+
+// format splits a number in groups separated by spaces, remaining digits are grouped by 4
+func format(number string, groups []int) string {
+	parts := []string{}
+	for _, size := range groups {
+		if len(number) < size {
+			break
+		}
+		parts = append(parts, number[:size])
+		number = number[size:]
+	}
+	for len(number) > 4 {
+		parts = append(parts, number[:4])
+		number = number[4:]
+	}
+	if number != "" {
+		parts = append(parts, number)
+	}
+	return strings.Join(parts, " ")
+}
+
+func digits(s string) string {
+	var sb strings.Builder
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+func randomDigits(r *rand.Rand, length int) string {
+	var sb strings.Builder
+	for i := 0; i < length; i++ {
+		sb.WriteByte(byte('0' + r.Intn(10)))
+	}
+	return sb.String()
+}
+
+// Factory create a mask from a yaml config
+func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (model.MaskEngine, bool, error) {
+	if conf.Mask.CreditCard != nil {
+		// set differents seeds for differents jsonpath
+		h := fnv.New64a()
+		h.Write([]byte(conf.Selector.Jsonpath))
+		seed += int64(h.Sum64())
+		mask, err := NewMask(*conf.Mask.CreditCard, seed)
+		return mask, true, err
+	}
+	return nil, false, nil
+}
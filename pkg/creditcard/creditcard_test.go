@@ -0,0 +1,109 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+package creditcard
+
+import (
+	"testing"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskingShouldGenerateValidPAN(t *testing.T) {
+	for _, n := range networks {
+		mask, err := NewMask(model.CreditCardType{Networks: []string{n.name}}, 42)
+		assert.Nil(t, err)
+		for i := 0; i < 20; i++ {
+			result, err := mask.Mask("")
+			assert.Nil(t, err)
+			pan := result.(string)
+			assert.Len(t, pan, n.length, pan)
+			assert.True(t, valid(pan), pan)
+			detected, ok := detect(pan)
+			assert.True(t, ok, pan)
+			assert.Equal(t, n.name, detected.name, pan)
+		}
+	}
+}
+
+func TestMaskingShouldKeepBINAndLast4(t *testing.T) {
+	mask, err := NewMask(model.CreditCardType{KeepBIN: 6, KeepLast4: true, Spaces: true}, 42)
+	assert.Nil(t, err)
+
+	for i := 0; i < 20; i++ {
+		result, err := mask.Mask("3782 822463 10005")
+		assert.Nil(t, err)
+		assert.Regexp(t, `^3782 82[0-9]{4} [0-9]0005$`, result)
+		assert.True(t, valid(digits(result.(string))))
+	}
+}
+
+func TestMaskingShouldGenerateConsistentCVVAndExpiry(t *testing.T) {
+	cvv, err := NewMask(model.CreditCardType{Generate: CVV, PANField: "pan"}, 42)
+	assert.Nil(t, err)
+	expiry, err := NewMask(model.CreditCardType{Generate: Expiry, PANField: "pan", ExpiryFormat: "2006-01"}, 42)
+	assert.Nil(t, err)
+
+	amex := model.NewDictionary().With("pan", "378282246310005")
+	first, err := cvv.Mask("123", amex)
+	assert.Nil(t, err)
+	assert.Regexp(t, `^[0-9]{4}$`, first)
+	second, err := cvv.Mask("456", amex)
+	assert.Nil(t, err)
+	assert.Equal(t, first, second)
+
+	visa, err := cvv.Mask("123", model.NewDictionary().With("pan", "4111111111111111"))
+	assert.Nil(t, err)
+	assert.Regexp(t, `^[0-9]{3}$`, visa)
+
+	date, err := expiry.Mask("01/21", amex)
+	assert.Nil(t, err)
+	assert.Regexp(t, `^20(27|28|29|30)-(0[1-9]|1[0-2])$`, date)
+}
+
+func TestMaskingShouldGenerateExpiryAfterReferenceDate(t *testing.T) {
+	expiry, err := NewMask(model.CreditCardType{Generate: Expiry, PANField: "pan", ExpiryFormat: "2006-01", ReferenceDate: "2030-06-15"}, 42)
+	assert.Nil(t, err)
+
+	visa := model.NewDictionary().With("pan", "4111111111111111")
+	first, err := expiry.Mask(nil, visa)
+	assert.Nil(t, err)
+	assert.Regexp(t, `^20(31|32|33|34)-(0[1-9]|1[0-2])$`, first)
+
+	other, err := NewMask(model.CreditCardType{Generate: Expiry, PANField: "pan", ExpiryFormat: "2006-01", ReferenceDate: "2030-06-15"}, 42)
+	assert.Nil(t, err)
+	second, err := other.Mask(nil, visa)
+	assert.Nil(t, err)
+	assert.Equal(t, first, second)
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "4111 1111 1111 1111", format("4111111111111111", []int{4, 4, 4, 4}))
+	assert.Equal(t, "3056 930902 5904", format("30569309025904", []int{4, 6, 4}))
+	assert.Equal(t, "4111 1111 1111 1111 111", format("4111111111111111111", []int{4, 4, 4, 4}))
+}
+
+func TestNewMaskShouldRejectInvalidConfiguration(t *testing.T) {
+	_, err := NewMask(model.CreditCardType{Networks: []string{"unknown"}}, 42)
+	assert.NotNil(t, err)
+	_, err = NewMask(model.CreditCardType{KeepBIN: 4}, 42)
+	assert.NotNil(t, err)
+	_, err = NewMask(model.CreditCardType{Generate: "pin"}, 42)
+	assert.NotNil(t, err)
+	_, err = NewMask(model.CreditCardType{Generate: Expiry, ReferenceDate: "15/06/2030"}, 42)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,72 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package creditcard
+
+import "strconv"
+
+// iin is a range of Issuer Identification Numbers, low and high have the same number of digits
+type iin struct {
+	low  int
+	high int
+}
+
+// network describes the numbers issued by a card network
+type network struct {
+	name   string
+	ranges []iin
+	length int
+	cvv    int
+	groups []int
+}
+
+// nolint: gochecknoglobals
+var networks = []network{
+	{"visa", []iin{{4, 4}}, 16, 3, []int{4, 4, 4, 4}},
+	{"mastercard", []iin{{51, 55}, {2221, 2720}}, 16, 3, []int{4, 4, 4, 4}},
+	{"amex", []iin{{34, 34}, {37, 37}}, 15, 4, []int{4, 6, 5}},
+	{"discover", []iin{{6011, 6011}, {644, 649}, {65, 65}}, 16, 3, []int{4, 4, 4, 4}},
+	{"jcb", []iin{{3528, 3589}}, 16, 3, []int{4, 4, 4, 4}},
+	{"dinersclub", []iin{{300, 305}, {36, 36}, {38, 39}}, 14, 3, []int{4, 6, 4}},
+	{"unionpay", []iin{{62, 62}}, 16, 3, []int{4, 4, 4, 4}},
+}
+
+// detect returns the network of a card number, the longest matching prefix wins
+func detect(number string) (network, bool) {
+	found, foundLength := network{}, 0
+	for _, n := range networks {
+		for _, r := range n.ranges {
+			size := len(strconv.Itoa(r.low))
+			if len(number) < size || size <= foundLength {
+				continue
+			}
+			if prefix, err := strconv.Atoi(number[:size]); err == nil && prefix >= r.low && prefix <= r.high {
+				found, foundLength = n, size
+			}
+		}
+	}
+	return found, foundLength > 0
+}
+
+func networkByName(name string) (network, bool) {
+	for _, n := range networks {
+		if n.name == name {
+			return n, true
+		}
+	}
+	return network{}, false
+}
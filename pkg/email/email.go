@@ -0,0 +1,194 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package email
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/cgi-fr/pimo/pkg/maskingdata"
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/cgi-fr/pimo/pkg/template"
+	"github.com/rs/zerolog/log"
+)
+
+// maxLocalLength is the maximum length of the local part of an address (RFC 5321)
+const maxLocalLength = 64
+
+// maxLabelLength is the maximum length of a label of a domain name (RFC 1035)
+const maxLabelLength = 63
+
+// DefaultDomains is the list of domains used when none is configured
+// nolint: gochecknoglobals
+var DefaultDomains = []string{
+	"gmail.com",
+	"yahoo.fr",
+	"hotmail.fr",
+	"outlook.fr",
+	"orange.fr",
+	"free.fr",
+	"sfr.fr",
+	"laposte.net",
+	"wanadoo.fr",
+	"icloud.com",
+}
+
+// MaskEngine is a mask to generate valid email addresses
+type MaskEngine struct {
+	rand *rand.Rand
+	model.RandState
+	local      *template.Engine
+	domains    []string
+	keepDomain bool
+}
+
+// NewMask create a MaskEngine, local is a template for the local part of addresses
+func NewMask(local string, domains []string, keepDomain bool, seed int64) (MaskEngine, error) {
+	var temp *template.Engine
+	if local != "" {
+		var err error
+		if temp, err = template.NewEngine(local); err != nil {
+			return MaskEngine{}, err
+		}
+	}
+	if len(domains) == 0 {
+		domains = DefaultDomains
+	}
+	source := model.NewRandSource(seed)
+	// nolint: gosec
+	return MaskEngine{rand.New(source), model.NewRandState(source), temp, domains, keepDomain}, nil
+}
+
+// Mask returns a new email address
+func (me MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.Entry, error) {
+	return me.MaskRetry(e, 0, context...)
+}
+
+// MaskRetry returns a new email address, a number is appended to the local part if attempt is not zero
+func (me MaskEngine) MaskRetry(e model.Entry, attempt int, context ...model.Dictionary) (model.Entry, error) {
+	log.Info().Msg("Mask email")
+
+	var local string
+	if me.local != nil {
+		if len(context) == 0 {
+			context = []model.Dictionary{model.NewDictionary()}
+		}
+		var output bytes.Buffer
+		if err := me.local.Execute(&output, context[0].Unordered()); err != nil {
+			return nil, err
+		}
+		local = output.String()
+	} else {
+		firstname := maskingdata.MapData["nameFR"][me.rand.Intn(len(maskingdata.MapData["nameFR"]))]
+		surname := maskingdata.SurnameFR[me.rand.Intn(len(maskingdata.SurnameFR))]
+		local = firstname + "." + surname
+	}
+
+	suffix := ""
+	if attempt > 0 {
+		suffix = strconv.Itoa(attempt)
+	}
+	local = NormalizeLocal(local, maxLocalLength-len(suffix)) + suffix
+
+	domain := ""
+	if original, ok := e.(string); ok && me.keepDomain {
+		if i := strings.LastIndex(original, "@"); i >= 0 {
+			domain = NormalizeDomain(original[i+1:])
+		}
+	}
+	// the original value is not an address or its domain is not valid
+	if domain == "" {
+		domain = NormalizeDomain(me.domains[me.rand.Intn(len(me.domains))])
+	}
+
+	return local + "@" + domain, nil
+}
+
+// NormalizeLocal transforms a string into a valid dot-atom local part of an address (RFC 5322),
+// accents are removed and forbidden characters are dropped
+func NormalizeLocal(s string, maxLength int) string {
+	var sb strings.Builder
+	dot := true // forbid a dot at the start
+	for _, r := range strings.ToLower(template.NoAccent(s)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '+':
+			sb.WriteRune(r)
+			dot = false
+		case r == '.', r == ' ', r == '\t':
+			if !dot {
+				sb.WriteRune('.')
+				dot = true
+			}
+		}
+		if sb.Len() >= maxLength {
+			break
+		}
+	}
+	result := strings.TrimRight(sb.String(), ".")
+	if len(result) > maxLength {
+		result = strings.TrimRight(result[:maxLength], ".")
+	}
+	if result == "" {
+		return "user"
+	}
+	return result
+}
+
+// NormalizeDomain removes accents and forbidden characters from a domain name,
+// an empty string is returned if the result is not a valid domain name with at least two labels
+func NormalizeDomain(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(template.NoAccent(s)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '.' {
+			sb.WriteRune(r)
+		}
+	}
+	domain := sb.String()
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return ""
+	}
+	for _, label := range labels {
+		if label == "" || len(label) > maxLabelLength || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return ""
+		}
+	}
+	return domain
+}
+
+// Factory create a mask from a yaml config
+func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (model.MaskEngine, bool, error) {
+	if conf.Mask.Email != nil {
+		// set differents seeds for differents jsonpath
+		h := fnv.New64a()
+		h.Write([]byte(conf.Selector.Jsonpath))
+		seed += int64(h.Sum64())
+		for _, domain := range conf.Mask.Email.Domains {
+			if NormalizeDomain(domain) == "" {
+				return nil, true, fmt.Errorf("invalid domain '%s'", domain)
+			}
+		}
+		mask, err := NewMask(conf.Mask.Email.Local, conf.Mask.Email.Domains, conf.Mask.Email.KeepDomain, seed)
+		return mask, true, err
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,117 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package email
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+var validEmail = regexp.MustCompile(`^[a-z0-9_+-]+(\.[a-z0-9_+-]+)*@[a-z0-9-]+(\.[a-z0-9-]+)+$`)
+
+func TestMaskingShouldGenerateValidEmail(t *testing.T) {
+	mask, err := NewMask("", nil, false, 42)
+	assert.Nil(t, err)
+
+	for i := 0; i < 100; i++ {
+		result, err := mask.Mask("john.doe@example.com")
+		assert.Nil(t, err)
+		assert.Regexp(t, validEmail, result)
+	}
+}
+
+func TestMaskingShouldUseLocalTemplateAndKeepDomain(t *testing.T) {
+	mask, err := NewMask("{{.first}} {{.last}}", nil, true, 42)
+	assert.Nil(t, err)
+
+	context := model.NewDictionary().With("first", "Jérôme").With("last", "D'Arc")
+	result, err := mask.Mask("jd@Example.com", context)
+	assert.Nil(t, err)
+	assert.Equal(t, "jerome.darc@example.com", result)
+}
+
+func TestKeepDomainShouldFallBackToDomainsWithoutValidDomain(t *testing.T) {
+	mask, err := NewMask("{{.name}}", []string{"example.com"}, true, 42)
+	assert.Nil(t, err)
+
+	context := model.NewDictionary().With("name", "bob")
+	for _, original := range []model.Entry{"bob", "bob@", "bob@!!!", "bob@...", "bob@x..com", "bob@-x.com", "bob@x-.com", "bob@localhost", 42} {
+		result, err := mask.Mask(original, context)
+		assert.Nil(t, err)
+		assert.Equal(t, "bob@example.com", result)
+	}
+}
+
+func TestMaskRetryShouldAppendAttempt(t *testing.T) {
+	mask, err := NewMask("{{.name}}", []string{"example.com"}, false, 42)
+	assert.Nil(t, err)
+
+	context := model.NewDictionary().With("name", "bob")
+	result, err := mask.MaskRetry("bob@test.com", 2, context)
+	assert.Nil(t, err)
+	assert.Equal(t, "bob2@example.com", result)
+}
+
+func TestMaskingWithUniqueCacheShouldAvoidCollisions(t *testing.T) {
+	mask, err := NewMask("{{.name}}", []string{"example.com"}, false, 42)
+	assert.Nil(t, err)
+	cached := model.NewUniqueMaskCacheEngine(model.NewUniqueMemCache(), mask)
+
+	context := model.NewDictionary().With("name", "bob")
+	first, err := cached.Mask("bob@a.com", context)
+	assert.Nil(t, err)
+	second, err := cached.Mask("bob@b.com", context)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "bob@example.com", first)
+	assert.Equal(t, "bob1@example.com", second)
+}
+
+func TestNormalizeLocal(t *testing.T) {
+	assert.Equal(t, "jean-pierre.dupont", NormalizeLocal(" Jean-Pierre  Dupont. ", 64))
+	assert.Equal(t, "user", NormalizeLocal("@@@", 64))
+	assert.Equal(t, "abc", NormalizeLocal("abc.def", 4))
+}
+
+func TestFactoryShouldCreateAMask(t *testing.T) {
+	maskingConfig := model.Masking{Mask: model.MaskType{Email: &model.EmailType{}}}
+	mask, present, err := Factory(maskingConfig, 0, nil)
+	assert.NotNil(t, mask, "shouldn't be nil")
+	assert.True(t, present, "should be true")
+	assert.Nil(t, err, "error should be nil")
+}
+
+func TestFactoryShouldNotCreateAMaskFromAnEmptyConfig(t *testing.T) {
+	maskingConfig := model.Masking{Mask: model.MaskType{}}
+	mask, present, err := Factory(maskingConfig, 0, nil)
+	assert.Nil(t, mask, "should be nil")
+	assert.False(t, present, "should be false")
+	assert.Nil(t, err, "error should be nil")
+}
+
+func TestNormalizeDomainShouldValidateLabels(t *testing.T) {
+	assert.Equal(t, "societe-generale.fr", NormalizeDomain("Société-Générale.fr"))
+	assert.Equal(t, "", NormalizeDomain("x..com"))
+	assert.Equal(t, "", NormalizeDomain("-x.com"))
+	assert.Equal(t, "", NormalizeDomain("localhost"))
+	assert.Equal(t, "", NormalizeDomain(strings.Repeat("a", 64)+".com"))
+}
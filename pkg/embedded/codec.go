@@ -0,0 +1,125 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package embedded
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/cgi-fr/pimo/pkg/jsonline"
+	"github.com/cgi-fr/pimo/pkg/model"
+)
+
+// encoder re-encodes a dictionary to the representation of the decoded value
+type encoder func(model.Dictionary) (string, error)
+
+// codec decodes a string to a dictionary, the returned encoder keeps details of the original representation
+type codec func(string) (model.Dictionary, encoder, error)
+
+// nolint: gochecknoglobals
+var codecs = map[string]codec{
+	"json":        decodeJSON,
+	"base64+json": decodeBase64JSON,
+	"xml":         decodeXML,
+	"url-query":   decodeURLQuery,
+}
+
+func decodeJSON(value string) (model.Dictionary, encoder, error) {
+	dict, err := jsonline.JSONToDictionary([]byte(value))
+	return dict, encodeJSON, err
+}
+
+func encodeJSON(dict model.Dictionary) (string, error) {
+	result, err := json.Marshal(dict)
+	return string(result), err
+}
+
+func decodeBase64JSON(value string) (model.Dictionary, encoder, error) {
+	// the same variant of base64 is used to encode the result
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		content, err := encoding.DecodeString(value)
+		if err != nil {
+			continue
+		}
+		dict, err := jsonline.JSONToDictionary(content)
+		if err != nil {
+			return dict, nil, err
+		}
+		enc := encoding
+		return dict, func(dict model.Dictionary) (string, error) {
+			result, err := json.Marshal(dict)
+			return enc.EncodeToString(result), err
+		}, nil
+	}
+	return model.NewDictionary(), nil, fmt.Errorf("value is not encoded in base64")
+}
+
+// decodeURLQuery decodes parameters of a query string, a parameter repeated in the query is decoded to an array
+func decodeURLQuery(value string) (model.Dictionary, encoder, error) {
+	dict := model.NewDictionary()
+	if value == "" {
+		return dict, encodeURLQuery, nil
+	}
+	for _, parameter := range strings.Split(value, "&") {
+		parts := strings.SplitN(parameter, "=", 2)
+		key, err := url.QueryUnescape(parts[0])
+		if err != nil {
+			return dict, nil, err
+		}
+		var entry model.Entry = ""
+		if len(parts) == 2 {
+			if entry, err = url.QueryUnescape(parts[1]); err != nil {
+				return dict, nil, err
+			}
+		}
+		if previous, ok := dict.GetValue(key); ok {
+			if values, isSlice := previous.([]model.Entry); isSlice {
+				entry = append(values, entry)
+			} else {
+				entry = []model.Entry{previous, entry}
+			}
+		}
+		dict.Set(key, entry)
+	}
+	return dict, encodeURLQuery, nil
+}
+
+func encodeURLQuery(dict model.Dictionary) (string, error) {
+	parameters := []string{}
+	iter := dict.EntriesIter()
+	for {
+		pair, ok := iter()
+		if !ok {
+			break
+		}
+		values, ok := pair.Value.([]model.Entry)
+		if !ok {
+			values = []model.Entry{pair.Value}
+		}
+		for _, value := range values {
+			if value == nil {
+				value = ""
+			}
+			parameters = append(parameters, url.QueryEscape(pair.Key)+"="+url.QueryEscape(fmt.Sprint(value)))
+		}
+	}
+	return strings.Join(parameters, "&"), nil
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package embedded
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// MaskEngine is a mask to apply a masking pipeline on a structure encoded in a string
+type MaskEngine struct {
+	codec    codec
+	pipeline model.Pipeline
+}
+
+// NewMask create a MaskEngine, codec is the name of the representation of the structure
+func NewMask(codecName string, seed int64, caches map[string]model.Cache, masking ...model.Masking) (MaskEngine, error) {
+	c, ok := codecs[codecName]
+	if !ok {
+		names := []string{}
+		for name := range codecs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return MaskEngine{}, fmt.Errorf("codec '%s' is not supported, use one of %s", codecName, strings.Join(names, ", "))
+	}
+	definition := model.Definition{Seed: seed + 1, Masking: masking}
+	pipeline, _, err := model.BuildPipeline(model.NewPipeline(nil), definition, caches)
+	return MaskEngine{c, pipeline}, err
+}
+
+// Mask decodes the value, applies the masking pipeline and encodes the result in the same representation
+func (me MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.Entry, error) {
+	value, ok := e.(string)
+	if !ok {
+		log.Warn().Msg("Mask embedded - ignored non string value")
+		return e, nil
+	}
+
+	log.Info().Msg("Mask embedded")
+
+	dict, encode, err := me.codec(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []model.Dictionary
+	err = me.pipeline.
+		WithSource(model.NewSourceFromSlice([]model.Dictionary{dict})).
+		AddSink(model.NewSinkToSlice(&result)).
+		Run()
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+
+	return encode(result[0])
+}
+
+// Factory create a mask from a yaml config
+func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (model.MaskEngine, bool, error) {
+	if conf.Mask.Embedded != nil {
+		// set differents seeds for differents jsonpath
+		h := fnv.New64a()
+		h.Write([]byte(conf.Selector.Jsonpath))
+		seed += int64(h.Sum64())
+		mask, err := NewMask(conf.Mask.Embedded.Codec, seed, caches, conf.Mask.Embedded.Masking...)
+		return mask, true, err
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,128 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+package embedded
+
+import (
+	"testing"
+
+	"github.com/cgi-fr/pimo/pkg/constant"
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodecsShouldKeepRepresentation(t *testing.T) {
+	for name, value := range map[string]string{
+		"json":        `{"name":"john","tags":["a","b"],"address":{"city":"Nantes"}}`,
+		"base64+json": "eyJuYW1lIjoiam9obiJ9",
+		"url-query":   "name=john+doe&tag=a&tag=b&empty=",
+		"xml":         `<?xml version="1.0" encoding="UTF-8"?><person id="1"><name>john &amp; co</name><tag>a</tag><tag>b</tag><city zip="44000">Nantes</city></person>`,
+	} {
+		dict, encode, err := codecs[name](value)
+		assert.Nil(t, err, name)
+		result, err := encode(dict)
+		assert.Nil(t, err, name)
+		assert.Equal(t, value, result, name)
+	}
+}
+
+func TestBase64ShouldKeepVariant(t *testing.T) {
+	dict, encode, err := decodeBase64JSON("eyJhIjoiYj8_In0")
+	assert.Nil(t, err)
+	assert.Equal(t, "b??", dict.Get("a"))
+	result, err := encode(dict)
+	assert.Nil(t, err)
+	assert.Equal(t, "eyJhIjoiYj8_In0", result)
+}
+
+func TestXMLShouldDecodeStructure(t *testing.T) {
+	dict, _, err := decodeXML(`<person id="1"><name>john</name><tag>a</tag><tag>b</tag></person>`)
+	assert.Nil(t, err)
+	person := dict.Get("person").(model.Dictionary)
+	assert.Equal(t, "1", person.Get("@id"))
+	assert.Equal(t, "john", person.Get("name"))
+	assert.Equal(t, []model.Entry{"a", "b"}, person.Get("tag"))
+
+	_, _, err = decodeXML("not xml")
+	assert.NotNil(t, err)
+}
+
+func TestXMLShouldKeepNamespacesCommentsAndMixedContent(t *testing.T) {
+	value := `<?xml version="1.0"?>
+<!-- request -->
+<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope" xmlns="urn:person">
+  <soap:Body soap:encodingStyle="urn:enc">
+    <name>Bob</name><!-- c --><age>3</age>
+    <?audit level="1"?>
+    <note>Hello <b>Bob</b>, see you <i>soon</i>!</note>
+  </soap:Body>
+</soap:Envelope>
+`
+	dict, encode, err := decodeXML(value)
+	assert.Nil(t, err)
+	envelope := dict.Get("soap:Envelope").(model.Dictionary)
+	assert.Equal(t, "http://www.w3.org/2003/05/soap-envelope", envelope.Get("@xmlns:soap"))
+	assert.Equal(t, "urn:person", envelope.Get("@xmlns"))
+	body := envelope.Get("soap:Body").(model.Dictionary)
+	assert.Equal(t, "urn:enc", body.Get("@soap:encodingStyle"))
+	assert.Equal(t, "Bob", body.Get("name"))
+	assert.Equal(t, []model.Entry{"Hello", ", see you", "!"}, body.Get("note").(model.Dictionary).Get("#text"))
+
+	result, err := encode(dict)
+	assert.Nil(t, err)
+	assert.Equal(t, value, result)
+
+	_, _, err = decodeXML("<a><b></a></b>")
+	assert.NotNil(t, err)
+	_, _, err = decodeXML("<a></a><b></b>")
+	assert.NotNil(t, err)
+}
+
+func TestMaskingShouldApplyNestedMasking(t *testing.T) {
+	model.InjectMaskFactories([]model.MaskFactory{constant.Factory})
+
+	for _, tt := range []struct {
+		codec    string
+		jsonpath string
+		value    string
+		expected string
+	}{
+		{"json", "name", `{"name":"john","age":42}`, `{"name":"masked","age":42}`},
+		{"base64+json", "name", "eyJuYW1lIjoiam9obiJ9", "eyJuYW1lIjoibWFza2VkIn0="},
+		{"url-query", "name", "name=john&lang=fr", "name=masked&lang=fr"},
+		{"xml", "person.name", `<person><name>john</name><age>42</age></person>`, `<person><name>masked</name><age>42</age></person>`},
+		{
+			"xml", "soap:Envelope.soap:Body.name",
+			`<soap:Envelope xmlns:soap="urn:soap"><soap:Body><name>Bob</name><!-- c --><age>3</age></soap:Body></soap:Envelope>`,
+			`<soap:Envelope xmlns:soap="urn:soap"><soap:Body><name>masked</name><!-- c --><age>3</age></soap:Body></soap:Envelope>`,
+		},
+		{"xml", "p.#text", `<p>Hello <b>Bob</b>, bye</p>`, `<p>masked <b>Bob</b>masked</p>`},
+	} {
+		mask, err := NewMask(tt.codec, 42, map[string]model.Cache{}, model.Masking{
+			Selector: model.SelectorType{Jsonpath: tt.jsonpath},
+			Mask:     model.MaskType{Constant: "masked"},
+		})
+		assert.Nil(t, err, tt.codec)
+		result, err := mask.Mask(tt.value)
+		assert.Nil(t, err, tt.codec)
+		assert.Equal(t, tt.expected, result, tt.codec)
+	}
+}
+
+func TestNewMaskShouldRejectUnknownCodec(t *testing.T) {
+	_, err := NewMask("yaml", 42, map[string]model.Cache{})
+	assert.NotNil(t, err)
+}
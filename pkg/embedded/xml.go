@@ -0,0 +1,324 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package embedded
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+)
+
+const (
+	attributePrefix = "@"
+	textKey         = "#text"
+)
+
+const (
+	markupItem = iota // comment, processing instruction or whitespace, written as is
+	textItem          // text of the element, the value of #text
+	childItem         // child element
+)
+
+// xmlItem is a part of the content of an element in the original document
+type xmlItem struct {
+	kind   int
+	markup string
+	lead   string // whitespaces around a text
+	trail  string
+	index  int    // index of a text, or occurrence of a child element with this name
+	name   string // name of a child element
+	layout *xmlLayout
+}
+
+// xmlLayout keeps the order of texts, comments and children of an element, that a dictionary can't hold
+type xmlLayout struct {
+	items []xmlItem
+}
+
+// nolint: gochecknoglobals
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// decodeXML decodes a XML document to a dictionary with the root element as single key.
+// Attributes are keys prefixed by @, an element with only text is a string, otherwise its text is the key #text
+// (an array if the text is split by child elements or comments), repeated elements are arrays.
+// Names keep their namespace prefix (e.g. soap:Body) and namespace declarations are attributes (e.g. @xmlns:soap),
+// comments, processing instructions and whitespaces are restored by the encoder at their original position.
+func decodeXML(value string) (model.Dictionary, encoder, error) {
+	decoder := xml.NewDecoder(strings.NewReader(value))
+	for {
+		offset := decoder.InputOffset()
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			return model.NewDictionary(), nil, fmt.Errorf("value is not a XML document")
+		}
+		if err != nil {
+			return model.NewDictionary(), nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		name := qualifiedName(start.Name)
+		root, layout, err := decodeElement(decoder, start)
+		if err != nil {
+			return model.NewDictionary(), nil, err
+		}
+		prolog, epilog := value[:offset], value[decoder.InputOffset():]
+		if err := checkEpilog(decoder); err != nil {
+			return model.NewDictionary(), nil, err
+		}
+		return model.NewDictionary().With(name, root), func(dict model.Dictionary) (string, error) {
+			var sb strings.Builder
+			sb.WriteString(prolog)
+			if err := encodeElements(&sb, dict, map[string][]*xmlLayout{name: {layout}}, map[string]int{}); err != nil {
+				return "", err
+			}
+			sb.WriteString(epilog)
+			return sb.String(), nil
+		}, nil
+	}
+}
+
+// checkEpilog returns an error if something else than comments, processing instructions or whitespaces follows the root element
+func checkEpilog(decoder *xml.Decoder) error {
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement, xml.EndElement:
+			return fmt.Errorf("value is not a XML document, it has several root elements")
+		case xml.CharData:
+			if strings.TrimSpace(string(t)) != "" {
+				return fmt.Errorf("value is not a XML document, text found after the root element")
+			}
+		}
+	}
+}
+
+func qualifiedName(name xml.Name) string {
+	// raw tokens keep the prefix of the name in Space
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+func decodeElement(decoder *xml.Decoder, start xml.StartElement) (model.Entry, *xmlLayout, error) {
+	element := model.NewDictionary()
+	for _, attr := range start.Attr {
+		element.Set(attributePrefix+qualifiedName(attr.Name), attr.Value)
+	}
+
+	layout := &xmlLayout{}
+	texts := []model.Entry{}
+	occurrences := map[string]int{}
+	var pending strings.Builder // consecutive character data, split by CDATA sections
+	flush := func() {
+		content := pending.String()
+		pending.Reset()
+		core := strings.TrimSpace(content)
+		if core == "" {
+			if content != "" {
+				layout.items = append(layout.items, xmlItem{kind: markupItem, markup: content})
+			}
+			return
+		}
+		lead := content[:strings.Index(content, core)]
+		trail := content[len(lead)+len(core):]
+		layout.items = append(layout.items, xmlItem{kind: textItem, lead: lead, trail: trail, index: len(texts)})
+		texts = append(texts, core)
+	}
+
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			return nil, nil, fmt.Errorf("element '%s' is not closed", qualifiedName(start.Name))
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		switch t := token.(type) {
+		case xml.CharData:
+			pending.Write(t)
+		case xml.Comment:
+			flush()
+			layout.items = append(layout.items, xmlItem{kind: markupItem, markup: "<!--" + string(t) + "-->"})
+		case xml.ProcInst:
+			flush()
+			markup := "<?" + t.Target
+			if len(t.Inst) > 0 {
+				markup += " " + string(t.Inst)
+			}
+			layout.items = append(layout.items, xmlItem{kind: markupItem, markup: markup + "?>"})
+		case xml.Directive:
+			flush()
+			layout.items = append(layout.items, xmlItem{kind: markupItem, markup: "<!" + string(t) + ">"})
+		case xml.StartElement:
+			flush()
+			child, childLayout, err := decodeElement(decoder, t)
+			if err != nil {
+				return nil, nil, err
+			}
+			name := qualifiedName(t.Name)
+			layout.items = append(layout.items, xmlItem{kind: childItem, name: name, index: occurrences[name], layout: childLayout})
+			occurrences[name]++
+			if previous, ok := element.GetValue(name); ok {
+				if values, isSlice := previous.([]model.Entry); isSlice {
+					element.Set(name, append(values, child))
+				} else {
+					element.Set(name, []model.Entry{previous, child})
+				}
+			} else {
+				element.Set(name, child)
+			}
+		case xml.EndElement:
+			if name := qualifiedName(t.Name); name != qualifiedName(start.Name) {
+				return nil, nil, fmt.Errorf("element '%s' is closed by '%s'", qualifiedName(start.Name), name)
+			}
+			flush()
+			if len(element.Unordered()) == 0 && len(texts) <= 1 {
+				if len(texts) == 0 {
+					return "", layout, nil
+				}
+				return texts[0], layout, nil
+			}
+			switch len(texts) {
+			case 0:
+			case 1:
+				element.Set(textKey, texts[0])
+			default:
+				element.Set(textKey, texts)
+			}
+			return element, layout, nil
+		}
+	}
+}
+
+// encodeElements writes the elements of the dictionary, layouts are the original layouts of elements by name,
+// written counts the elements already written by name
+func encodeElements(sb *strings.Builder, dict model.Dictionary, layouts map[string][]*xmlLayout, written map[string]int) error {
+	iter := dict.EntriesIter()
+	for {
+		pair, ok := iter()
+		if !ok {
+			return nil
+		}
+		if strings.HasPrefix(pair.Key, attributePrefix) || pair.Key == textKey {
+			continue
+		}
+		values := elementValues(pair.Value)
+		for i := written[pair.Key]; i < len(values); i++ {
+			var layout *xmlLayout
+			if i < len(layouts[pair.Key]) {
+				layout = layouts[pair.Key][i]
+			}
+			if err := encodeElement(sb, pair.Key, values[i], layout); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func elementValues(value model.Entry) []model.Entry {
+	if values, ok := value.([]model.Entry); ok {
+		return values
+	}
+	return []model.Entry{value}
+}
+
+// encodeElement writes an element, the content follows the original layout of the element if it is not nil
+func encodeElement(sb *strings.Builder, name string, value model.Entry, layout *xmlLayout) error {
+	sb.WriteString("<" + name)
+	element, isDict := value.(model.Dictionary)
+	texts := []model.Entry{value}
+	if isDict {
+		iter := element.EntriesIter()
+		for {
+			pair, ok := iter()
+			if !ok {
+				break
+			}
+			if strings.HasPrefix(pair.Key, attributePrefix) {
+				sb.WriteString(" " + strings.TrimPrefix(pair.Key, attributePrefix) + `="`)
+				if err := xml.EscapeText(sb, []byte(text(pair.Value))); err != nil {
+					return err
+				}
+				sb.WriteString(`"`)
+			}
+		}
+		texts = nil
+		if content, ok := element.GetValue(textKey); ok {
+			texts = elementValues(content)
+		}
+	} else {
+		element = model.NewDictionary()
+	}
+	sb.WriteString(">")
+
+	writtenTexts := 0
+	written := map[string]int{}
+	layouts := map[string][]*xmlLayout{}
+	if layout != nil {
+		for _, item := range layout.items {
+			switch item.kind {
+			case markupItem:
+				sb.WriteString(item.markup)
+			case textItem:
+				if item.index < len(texts) {
+					sb.WriteString(item.lead + textEscaper.Replace(text(texts[item.index])) + item.trail)
+					writtenTexts = item.index + 1
+				}
+			case childItem:
+				layouts[item.name] = append(layouts[item.name], item.layout)
+				if child, ok := element.GetValue(item.name); ok {
+					if values := elementValues(child); item.index < len(values) {
+						if err := encodeElement(sb, item.name, values[item.index], item.layout); err != nil {
+							return err
+						}
+						written[item.name] = item.index + 1
+					}
+				}
+			}
+		}
+	}
+
+	// texts and elements added by the masking are written at the end of the element
+	for _, content := range texts[writtenTexts:] {
+		sb.WriteString(textEscaper.Replace(text(content)))
+	}
+	if err := encodeElements(sb, element, layouts, written); err != nil {
+		return err
+	}
+	sb.WriteString("</" + name + ">")
+	return nil
+}
+
+func text(value model.Entry) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
@@ -52,10 +52,15 @@ func (ff1m MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.E
 
 	// Extract tweak from the Dictionary (context)
 	var tweak string
-	if context[0].Get(ff1m.tweakField) == nil {
-		tweak = ""
-	} else {
-		tweak = context[0].Get(ff1m.tweakField).(string)
+	if context[0].Get(ff1m.tweakField) != nil {
+		var err error
+		if tweak, err = model.ToString(context[0].Get(ff1m.tweakField)); err != nil {
+			return nil, err
+		}
+	}
+	value, err := model.ToString(e)
+	if err != nil {
+		return nil, err
 	}
 	// Get encryption key as byte array
 	envKey := os.Getenv(ff1m.keyFromEnv)
@@ -74,10 +79,10 @@ func (ff1m MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.E
 	var ciphertext string
 	if ff1m.decrypt {
 		// Decrypt targeted string
-		ciphertext, err = FF1.Decrypt(e.(string))
+		ciphertext, err = FF1.Decrypt(value)
 	} else {
 		// Encrypt targeted string
-		ciphertext, err = FF1.Encrypt(e.(string))
+		ciphertext, err = FF1.Encrypt(value)
 	}
 	if err != nil {
 		return nil, err
@@ -18,6 +18,7 @@
 package ff1
 
 import (
+	"encoding/json"
 	"os"
 	"testing"
 
@@ -98,3 +99,15 @@ func TestFactoryShouldReturnErrorOnWrongConfig(t *testing.T) {
 	assert.True(t, present, "should be true")
 	assert.EqualErrorf(t, err, "radix attribut is not optional", "should be nil")
 }
+
+func TestMaskingShouldEncryptNumber(t *testing.T) {
+	os.Setenv("FF1_ENCRYPTION_KEY", "70NZ2NWAqk9/A21vBPxqlA==")
+	context := model.NewDictionary().
+		With("tweak", json.Number("1234"))
+	ff1Mask := NewMask("FF1_ENCRYPTION_KEY", "tweak", 10, false)
+	fromNumber, err := ff1Mask.Mask(json.Number("123456"), context)
+	assert.Nil(t, err)
+	fromString, err := ff1Mask.Mask("123456", model.NewDictionary().With("tweak", "1234"))
+	assert.Nil(t, err)
+	assert.Equal(t, fromString, fromNumber, "Should be equal")
+}
@@ -18,6 +18,8 @@
 package fluxuri
 
 import (
+	"encoding/json"
+
 	"github.com/cgi-fr/pimo/pkg/model"
 	"github.com/cgi-fr/pimo/pkg/uri"
 	"github.com/rs/zerolog/log"
@@ -59,3 +61,13 @@ func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (mod
 	}
 	return nil, false, nil
 }
+
+// State returns the position of the cursor in the list
+func (me MaskEngine) State() (json.RawMessage, error) {
+	return json.Marshal(*me.Actual)
+}
+
+// Restore moves the cursor in the list to a saved position
+func (me MaskEngine) Restore(state json.RawMessage) error {
+	return json.Unmarshal(state, me.Actual)
+}
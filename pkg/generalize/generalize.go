@@ -0,0 +1,284 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package generalize
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/cgi-fr/pimo/pkg/template"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultFormat is the layout of dates when no format is configured
+const DefaultFormat = "2006-01-02"
+
+// nolint: gochecknoglobals
+var defaultTemplates = map[string]string{
+	"":        "[{{.Lower}};{{.Upper}})",
+	"week":    `{{.Year}}-W{{printf "%02d" .Week}}`,
+	"month":   `{{.Year}}-{{printf "%02d" .Month}}`,
+	"quarter": "{{.Year}}-Q{{.Quarter}}",
+	"year":    "{{.Year}}",
+}
+
+// Bucket is the data given to the label template. Numeric buckets include Lower and exclude Upper, bounds are
+// infinite outside of breakpoints. Date buckets are formatted dates, Upper is the last day of the period.
+type Bucket struct {
+	Lower    interface{}
+	Upper    interface{}
+	Midpoint interface{}
+	Year     int
+	Quarter  int
+	Month    int
+	Week     int
+}
+
+// MaskEngine is a mask to replace a number or a date by the interval containing it
+type MaskEngine struct {
+	width       float64
+	breakpoints []float64
+	period      string
+	age         bool
+	reference   time.Time
+	format      string
+	output      string
+	template    *template.Engine
+}
+
+// NewMask create a MaskEngine from the configuration, reference is the date used to compute ages
+func NewMask(conf model.GeneralizeType, reference time.Time) (MaskEngine, error) {
+	numeric := conf.Width != 0 || len(conf.Breakpoints) > 0
+	switch {
+	case conf.Width < 0:
+		return MaskEngine{}, fmt.Errorf("width of generalize must be positive")
+	case conf.Width != 0 && len(conf.Breakpoints) > 0:
+		return MaskEngine{}, fmt.Errorf("generalize accepts either a width or breakpoints")
+	case numeric && conf.Date != "":
+		return MaskEngine{}, fmt.Errorf("generalize accepts either a date period or a width or breakpoints")
+	case conf.Age && !numeric:
+		return MaskEngine{}, fmt.Errorf("age of generalize needs a width or breakpoints")
+	case !numeric && conf.Date == "":
+		return MaskEngine{}, fmt.Errorf("generalize needs a width, breakpoints or a date period")
+	}
+	if _, ok := defaultTemplates[conf.Date]; !ok {
+		return MaskEngine{}, fmt.Errorf("date period '%s' is not supported, use one of week, month, quarter, year", conf.Date)
+	}
+	if !sort.Float64sAreSorted(conf.Breakpoints) {
+		return MaskEngine{}, fmt.Errorf("breakpoints of generalize must be sorted")
+	}
+
+	output := conf.Output
+	switch output {
+	case "":
+		output = "range"
+	case "range", "midpoint", "lower":
+	default:
+		return MaskEngine{}, fmt.Errorf("output '%s' is not supported, use one of range, midpoint, lower", output)
+	}
+
+	format := conf.Format
+	if format == "" {
+		format = DefaultFormat
+	}
+
+	text := conf.Template
+	if text == "" {
+		text = defaultTemplates[conf.Date]
+	}
+	engine, err := template.NewEngine(text)
+	if err != nil {
+		return MaskEngine{}, err
+	}
+
+	return MaskEngine{conf.Width, conf.Breakpoints, conf.Date, conf.Age, reference, format, output, engine}, nil
+}
+
+// Mask return the interval containing the value, its midpoint or its lower bound
+func (me MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.Entry, error) {
+	log.Info().Msg("Mask generalize")
+	if e == nil {
+		return e, nil
+	}
+
+	if me.period != "" {
+		return me.generalizeDate(e)
+	}
+
+	var value float64
+	if me.age {
+		birth, err := model.ToTime(e, me.format)
+		if err != nil {
+			return nil, err
+		}
+		value = float64(Age(birth, me.reference))
+	} else {
+		var err error
+		if value, err = model.ToFloat64(e); err != nil {
+			return nil, err
+		}
+	}
+	return me.generalizeNumber(value)
+}
+
+func (me MaskEngine) generalizeNumber(value float64) (model.Entry, error) {
+	var lower, upper float64
+	if me.width != 0 {
+		// avoid floating point errors on values at the bound of a bucket (e.g. 0.3 with a width of 0.1)
+		quotient := value / me.width
+		if rounded := math.Round(quotient); math.Abs(quotient-rounded) < 1e-9 {
+			quotient = rounded
+		}
+		index := math.Floor(quotient)
+		lower, upper = roundTo(index*me.width, me.width), roundTo((index+1)*me.width, me.width)
+	} else {
+		index := sort.Search(len(me.breakpoints), func(i int) bool { return me.breakpoints[i] > value })
+		lower, upper = math.Inf(-1), math.Inf(1)
+		if index > 0 {
+			lower = me.breakpoints[index-1]
+		}
+		if index < len(me.breakpoints) {
+			upper = me.breakpoints[index]
+		}
+	}
+
+	bounded := !math.IsInf(lower, 0) && !math.IsInf(upper, 0)
+	switch me.output {
+	case "lower":
+		if math.IsInf(lower, 0) {
+			return nil, fmt.Errorf("value %v is lower than the first breakpoint", value)
+		}
+		return lower, nil
+	case "midpoint":
+		if !bounded {
+			return nil, fmt.Errorf("value %v is outside of breakpoints", value)
+		}
+		return (lower + upper) / 2, nil
+	}
+
+	bucket := Bucket{Lower: lower, Upper: upper}
+	if bounded {
+		bucket.Midpoint = (lower + upper) / 2
+	}
+	return me.label(bucket)
+}
+
+func (me MaskEngine) generalizeDate(e model.Entry) (model.Entry, error) {
+	date, err := model.ToTime(e, me.format)
+	if err != nil {
+		return nil, err
+	}
+	start, next := Period(date, me.period)
+	midpoint := start.Add(next.Sub(start) / 2)
+
+	switch me.output {
+	case "lower":
+		return start.Format(me.format), nil
+	case "midpoint":
+		return midpoint.Format(me.format), nil
+	}
+
+	year, week := start.ISOWeek()
+	if me.period != "week" {
+		year = start.Year()
+	}
+	return me.label(Bucket{
+		Lower:    start.Format(me.format),
+		Upper:    next.AddDate(0, 0, -1).Format(me.format),
+		Midpoint: midpoint.Format(me.format),
+		Year:     year,
+		Quarter:  (int(start.Month())-1)/3 + 1,
+		Month:    int(start.Month()),
+		Week:     week,
+	})
+}
+
+func (me MaskEngine) label(bucket Bucket) (model.Entry, error) {
+	var output bytes.Buffer
+	if err := me.template.Execute(&output, bucket); err != nil {
+		return nil, err
+	}
+	return output.String(), nil
+}
+
+// Period returns the first day of the period (week, month, quarter or year) containing the date, and the first day of the next one
+func Period(date time.Time, period string) (time.Time, time.Time) {
+	year, month, day := date.Date()
+	switch period {
+	case "week":
+		// ISO weeks start on monday
+		start := time.Date(year, month, day-(int(date.Weekday())+6)%7, 0, 0, 0, 0, date.Location())
+		return start, start.AddDate(0, 0, 7)
+	case "month":
+		start := time.Date(year, month, 1, 0, 0, 0, 0, date.Location())
+		return start, start.AddDate(0, 1, 0)
+	case "quarter":
+		start := time.Date(year, (month-1)/3*3+1, 1, 0, 0, 0, 0, date.Location())
+		return start, start.AddDate(0, 3, 0)
+	default:
+		start := time.Date(year, 1, 1, 0, 0, 0, 0, date.Location())
+		return start, start.AddDate(1, 0, 0)
+	}
+}
+
+// Age returns the number of full years between the birth date and the reference date
+func Age(birth time.Time, reference time.Time) int {
+	age := reference.Year() - birth.Year()
+	if reference.Month() < birth.Month() || (reference.Month() == birth.Month() && reference.Day() < birth.Day()) {
+		age--
+	}
+	return age
+}
+
+// roundTo removes floating point errors by rounding the value to the number of decimals of the width
+func roundTo(value float64, width float64) float64 {
+	decimals := 0
+	for w := width; w != math.Trunc(w) && decimals < 15; w *= 10 {
+		decimals++
+	}
+	factor := math.Pow(10, float64(decimals))
+	return math.Round(value*factor) / factor
+}
+
+// Factory create a mask from a yaml config
+func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (model.MaskEngine, bool, error) {
+	if conf.Mask.Generalize == nil {
+		return nil, false, nil
+	}
+	// the current date is never used, so a masked dataset does not change from one day to another
+	if conf.Mask.Generalize.Age && conf.Mask.Generalize.ReferenceDate == "" {
+		return nil, true, fmt.Errorf("age of generalize needs a referenceDate")
+	}
+	var reference time.Time
+	if conf.Mask.Generalize.ReferenceDate != "" {
+		format := conf.Mask.Generalize.Format
+		if format == "" {
+			format = DefaultFormat
+		}
+		var err error
+		if reference, err = model.ToTime(conf.Mask.Generalize.ReferenceDate, format); err != nil {
+			return nil, true, err
+		}
+	}
+	mask, err := NewMask(*conf.Mask.Generalize, reference)
+	return mask, true, err
+}
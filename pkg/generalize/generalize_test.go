@@ -0,0 +1,131 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package generalize
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func mask(t *testing.T, conf model.GeneralizeType, value model.Entry) model.Entry {
+	reference := time.Date(2021, 6, 15, 0, 0, 0, 0, time.UTC)
+	engine, err := NewMask(conf, reference)
+	assert.Nil(t, err)
+	result, err := engine.Mask(value)
+	assert.Nil(t, err)
+	return result
+}
+
+func TestMaskingShouldBucketNumbersWithWidth(t *testing.T) {
+	assert.Equal(t, "[20;30)", mask(t, model.GeneralizeType{Width: 10}, json.Number("25")))
+	assert.Equal(t, "[-10;0)", mask(t, model.GeneralizeType{Width: 10}, -3))
+	assert.Equal(t, "[0.3;0.4)", mask(t, model.GeneralizeType{Width: 0.1}, 0.3))
+	assert.Equal(t, "[12.5;15)", mask(t, model.GeneralizeType{Width: 2.5}, "13.7"))
+}
+
+func TestMaskingShouldBucketNumbersWithBreakpoints(t *testing.T) {
+	conf := model.GeneralizeType{Breakpoints: []float64{18, 25, 65}}
+	assert.Equal(t, "[-Inf;18)", mask(t, conf, 12))
+	assert.Equal(t, "[18;25)", mask(t, conf, 18))
+	assert.Equal(t, "[25;65)", mask(t, conf, 40.5))
+	assert.Equal(t, "[65;+Inf)", mask(t, conf, 70))
+}
+
+func TestMaskingShouldOutputMidpointOrLowerBound(t *testing.T) {
+	assert.Equal(t, 25.0, mask(t, model.GeneralizeType{Width: 10, Output: "midpoint"}, 21))
+	assert.Equal(t, 20.0, mask(t, model.GeneralizeType{Width: 10, Output: "lower"}, 21))
+	assert.Equal(t, 65.0, mask(t, model.GeneralizeType{Breakpoints: []float64{18, 65}, Output: "lower"}, 70))
+
+	engine, err := NewMask(model.GeneralizeType{Breakpoints: []float64{18, 65}, Output: "midpoint"}, time.Now())
+	assert.Nil(t, err)
+	_, err = engine.Mask(70)
+	assert.NotNil(t, err, "midpoint of an unbounded bucket should fail")
+}
+
+func TestMaskingShouldUseTemplate(t *testing.T) {
+	conf := model.GeneralizeType{Width: 10, Template: "{{.Lower}}-{{sub .Upper 1}}"}
+	assert.Equal(t, "20-29", mask(t, conf, 25))
+}
+
+func TestMaskingShouldBucketDates(t *testing.T) {
+	assert.Equal(t, "2021", mask(t, model.GeneralizeType{Date: "year"}, "2021-03-14"))
+	assert.Equal(t, "2021-Q1", mask(t, model.GeneralizeType{Date: "quarter"}, "2021-03-14T15:09:26Z"))
+	assert.Equal(t, "2021-03", mask(t, model.GeneralizeType{Date: "month"}, "2021-03-14"))
+	assert.Equal(t, "2021-W10", mask(t, model.GeneralizeType{Date: "week"}, "2021-03-14"))
+	assert.Equal(t, "2020-W53", mask(t, model.GeneralizeType{Date: "week"}, "2021-01-01"))
+	assert.Equal(t, "2021-01-01", mask(t, model.GeneralizeType{Date: "quarter", Output: "lower"}, "2021-03-14"))
+	assert.Equal(t, "[01/01/2021;31/03/2021]", mask(t, model.GeneralizeType{Date: "quarter", Format: "02/01/2006", Template: "[{{.Lower}};{{.Upper}}]"}, "14/03/2021"))
+	assert.Equal(t, "2021-03-08", mask(t, model.GeneralizeType{Date: "week", Output: "lower"}, time.Date(2021, 3, 14, 10, 0, 0, 0, time.UTC)))
+}
+
+func TestMaskingShouldBucketAges(t *testing.T) {
+	conf := model.GeneralizeType{Age: true, Breakpoints: []float64{18, 30, 50}}
+	assert.Equal(t, "[30;50)", mask(t, conf, "1990-06-15"))
+	assert.Equal(t, "[18;30)", mask(t, conf, "1991-06-16"))
+	assert.Equal(t, "[-Inf;18)", mask(t, conf, "2010-01-01"))
+}
+
+func TestMaskingShouldKeepNull(t *testing.T) {
+	assert.Nil(t, mask(t, model.GeneralizeType{Width: 10}, nil))
+}
+
+func TestNewMaskShouldRejectInvalidConfigurations(t *testing.T) {
+	for _, conf := range []model.GeneralizeType{
+		{},
+		{Width: -1},
+		{Width: 10, Breakpoints: []float64{1}},
+		{Width: 10, Date: "year"},
+		{Age: true},
+		{Date: "century"},
+		{Breakpoints: []float64{10, 5}},
+		{Width: 10, Output: "upper"},
+		{Width: 10, Template: "{{.Lower"},
+	} {
+		_, err := NewMask(conf, time.Now())
+		assert.NotNil(t, err, "%+v", conf)
+	}
+}
+
+func TestFactoryShouldCreateAMask(t *testing.T) {
+	maskingConfig := model.Masking{Mask: model.MaskType{Generalize: &model.GeneralizeType{Age: true, Width: 10, ReferenceDate: "2021-06-15"}}}
+	engine, present, err := Factory(maskingConfig, 0, nil)
+	assert.True(t, present)
+	assert.Nil(t, err)
+	result, err := engine.Mask("1990-06-15")
+	assert.Nil(t, err)
+	assert.Equal(t, "[30;40)", result)
+}
+
+func TestFactoryShouldRequireReferenceDateForAges(t *testing.T) {
+	maskingConfig := model.Masking{Mask: model.MaskType{Generalize: &model.GeneralizeType{Age: true, Width: 10}}}
+	_, present, err := Factory(maskingConfig, 0, nil)
+	assert.True(t, present)
+	assert.NotNil(t, err)
+}
+
+func TestFactoryShouldNotCreateAMaskFromAnEmptyConfig(t *testing.T) {
+	maskingConfig := model.Masking{Mask: model.MaskType{}}
+	mask, present, err := Factory(maskingConfig, 0, nil)
+	assert.Nil(t, mask)
+	assert.False(t, present)
+	assert.Nil(t, err)
+}
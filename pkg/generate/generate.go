@@ -0,0 +1,331 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package generate
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand"
+	"os"
+
+	"github.com/cgi-fr/pimo/pkg/jsonline"
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/cgi-fr/pimo/pkg/weightedchoice"
+	"github.com/rs/zerolog/log"
+)
+
+// Process generates the records of root entities for each input record, children records are nested
+// in their parent record or written in the file of their entity
+type Process struct {
+	roots    []*entity
+	entities []*entity
+}
+
+type entity struct {
+	name        string
+	field       string
+	file        string
+	cardinality cardinality
+	references  []reference
+	pipeline    model.Pipeline
+	children    []*entity
+	output      *os.File
+	sink        model.SinkProcess
+	written     int
+	resumed     bool
+}
+
+// entityState is the state of an entity saved in checkpoints
+type entityState struct {
+	Cardinality json.RawMessage `json:"cardinality"`
+	Written     int             `json:"written,omitempty"`
+}
+
+type reference struct {
+	field  string
+	parent model.Selector
+}
+
+type cardinality struct {
+	min     int
+	max     int
+	source  *model.RandSource
+	rand    *rand.Rand
+	chooser *weightedchoice.Chooser
+}
+
+func (c cardinality) next() int {
+	if c.chooser != nil {
+		return c.chooser.Pick().(int)
+	}
+	if c.max == c.min {
+		return c.min
+	}
+	return c.min + c.rand.Intn(c.max-c.min+1)
+}
+
+// NewProcess create a Process from the entities of a definition, masks of entities share the caches of the definition
+func NewProcess(entities []model.EntityDefinition, seed int64, caches map[string]model.Cache) (*Process, error) {
+	process := &Process{}
+	byName := map[string]*entity{}
+	for _, definition := range entities {
+		if definition.Name == "" {
+			return nil, fmt.Errorf("entity without name")
+		}
+		if _, exists := byName[definition.Name]; exists {
+			return nil, fmt.Errorf("entity '%s' is declared twice", definition.Name)
+		}
+
+		// set differents seeds for differents entities
+		h := fnv.New64a()
+		h.Write([]byte(definition.Name))
+		entitySeed := seed + int64(h.Sum64())
+
+		card, err := newCardinality(definition.Cardinality, entitySeed)
+		if err != nil {
+			return nil, fmt.Errorf("%s for entity '%s'", err.Error(), definition.Name)
+		}
+
+		pipeline, _, err := model.BuildPipeline(model.NewPipeline(nil), model.Definition{Seed: entitySeed, Masking: definition.Masking}, caches)
+		if err != nil {
+			return nil, fmt.Errorf("%s for entity '%s'", err.Error(), definition.Name)
+		}
+
+		e := &entity{
+			name:        definition.Name,
+			field:       definition.Field,
+			file:        definition.File,
+			cardinality: card,
+			pipeline:    pipeline,
+		}
+		if e.field == "" {
+			e.field = e.name
+		}
+		for _, ref := range definition.References {
+			e.references = append(e.references, reference{ref.Field, model.NewPathSelector(ref.Parent)})
+		}
+
+		if definition.Parent == "" {
+			process.roots = append(process.roots, e)
+		} else {
+			parent, ok := byName[definition.Parent]
+			if !ok {
+				return nil, fmt.Errorf("parent '%s' of entity '%s' should be declared before", definition.Parent, definition.Name)
+			}
+			parent.children = append(parent.children, e)
+		}
+		byName[e.name] = e
+		process.entities = append(process.entities, e)
+	}
+	return process, nil
+}
+
+func newCardinality(conf *model.CardinalityType, seed int64) (cardinality, error) {
+	source := model.NewRandSource(seed)
+	// nolint: gosec
+	card := cardinality{min: 1, max: 1, source: source, rand: rand.New(source)}
+	// an empty cardinality is the default cardinality
+	if conf == nil || (conf.Min == 0 && conf.Max == 0 && len(conf.Distribution) == 0) {
+		return card, nil
+	}
+	if len(conf.Distribution) > 0 {
+		choices := []weightedchoice.Choice{}
+		for _, w := range conf.Distribution {
+			if w.Count < 0 {
+				return card, fmt.Errorf("count of cardinality should be positive")
+			}
+			if w.Weight > 0 {
+				choices = append(choices, weightedchoice.Choice{Item: w.Count, Weight: w.Weight})
+			}
+		}
+		if len(choices) == 0 {
+			return card, fmt.Errorf("distribution of cardinality should have a positive weight")
+		}
+		chooser := weightedchoice.NewChooserFromSource(source, choices...)
+		card.chooser = &chooser
+		return card, nil
+	}
+	if conf.Min < 0 || conf.Max < conf.Min {
+		return card, fmt.Errorf("cardinality should have 0 <= min <= max")
+	}
+	card.min, card.max = conf.Min, conf.Max
+	return card, nil
+}
+
+// State returns the position of the random generators of cardinalities and the number of records written in
+// the file of each entity, masks of entities are saved with other masks
+func (p *Process) State() (json.RawMessage, error) {
+	states := map[string]entityState{}
+	for _, e := range p.entities {
+		state, err := e.cardinality.source.State()
+		if err != nil {
+			return nil, err
+		}
+		states[e.name] = entityState{state, e.written}
+	}
+	return json.Marshal(states)
+}
+
+// Restore moves the random generators of cardinalities to a saved position, files of entities will be truncated
+// to the saved number of records and appended
+func (p *Process) Restore(state json.RawMessage) error {
+	states := map[string]entityState{}
+	if err := json.Unmarshal(state, &states); err != nil {
+		return err
+	}
+	for _, e := range p.entities {
+		saved, ok := states[e.name]
+		if !ok {
+			return fmt.Errorf("entity '%s' not found in state", e.name)
+		}
+		if err := e.cardinality.source.Restore(saved.Cardinality); err != nil {
+			return err
+		}
+		e.written = saved.Written
+		e.resumed = true
+	}
+	return nil
+}
+
+// Open creates the files of entities written in their own stream, or reopens them after a restore
+func (p *Process) Open() error {
+	for _, e := range p.entities {
+		if e.file == "" || e.output != nil {
+			continue
+		}
+		var file *os.File
+		var err error
+		if e.resumed {
+			file, err = reopen(e.file, e.written)
+		} else {
+			file, err = os.Create(e.file)
+		}
+		if err != nil {
+			return err
+		}
+		e.output = file
+		e.sink = jsonline.NewSink(file)
+	}
+	return nil
+}
+
+// reopen opens a file to append records after its first lines, records written after the checkpoint are removed
+func reopen(path string, lines int) (*os.File, error) {
+	// nolint: gosec
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o666)
+	if err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(file)
+	var offset int64
+	for i := 0; i < lines; i++ {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("file '%s' has less than %d records written before the checkpoint", path, lines)
+		}
+		offset += int64(len(line))
+	}
+	if err := file.Truncate(offset); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// Close closes the files of entities
+func (p *Process) Close() error {
+	for _, e := range p.entities {
+		if e.output != nil {
+			if err := e.output.Close(); err != nil {
+				return err
+			}
+			e.output = nil
+		}
+	}
+	return nil
+}
+
+// ProcessDictionary generates the root entities with the input record as parent
+func (p *Process) ProcessDictionary(dictionary model.Dictionary, out model.Collector) error {
+	for _, root := range p.roots {
+		records, err := root.generate(dictionary)
+		if err != nil {
+			return err
+		}
+		if root.sink == nil {
+			for _, record := range records {
+				out.Collect(record)
+			}
+		}
+	}
+	return nil
+}
+
+// generate creates the records of the entity for a parent record, with their children
+func (e *entity) generate(parent model.Dictionary) ([]model.Dictionary, error) {
+	log.Debug().Str("entity", e.name).Msg("Generate records")
+	count := e.cardinality.next()
+	input := make([]model.Dictionary, count)
+	for i := range input {
+		record := model.NewDictionary()
+		for _, ref := range e.references {
+			value, _ := ref.parent.Read(parent)
+			record.Set(ref.field, value)
+		}
+		input[i] = record
+	}
+
+	var result []model.Dictionary
+	if err := e.pipeline.
+		WithSource(model.NewSourceFromSlice(input)).
+		AddSink(model.NewSinkToSlice(&result)).
+		Run(); err != nil {
+		return nil, err
+	}
+
+	for _, record := range result {
+		for _, child := range e.children {
+			records, err := child.generate(record)
+			if err != nil {
+				return nil, err
+			}
+			if child.sink == nil {
+				nested := make([]model.Entry, len(records))
+				for i, r := range records {
+					nested[i] = r
+				}
+				record.Set(child.field, nested)
+			}
+		}
+		if e.sink != nil {
+			if err := e.sink.ProcessDictionary(record); err != nil {
+				return nil, err
+			}
+			e.written++
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,210 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package generate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cgi-fr/pimo/pkg/add"
+	"github.com/cgi-fr/pimo/pkg/increment"
+	"github.com/cgi-fr/pimo/pkg/jsonline"
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type collector struct {
+	records []model.Dictionary
+}
+
+func (c *collector) Collect(d model.Dictionary) {
+	c.records = append(c.records, d)
+}
+
+func counter(jsonpath string) []model.Masking {
+	return []model.Masking{
+		{Selector: model.SelectorType{Jsonpath: jsonpath}, Mask: model.MaskType{Add: 0}},
+		{Selector: model.SelectorType{Jsonpath: jsonpath}, Mask: model.MaskType{Incremental: model.IncrementalType{Start: 1, Increment: 1}}},
+	}
+}
+
+func TestProcessShouldNestChildren(t *testing.T) {
+	model.InjectMaskFactories([]model.MaskFactory{increment.Factory})
+	model.InjectMaskContextFactories([]model.MaskContextFactory{add.Factory})
+	defer model.InjectMaskFactories(nil)
+	defer model.InjectMaskContextFactories(nil)
+
+	process, err := NewProcess([]model.EntityDefinition{
+		{Name: "customers", Cardinality: &model.CardinalityType{Min: 2, Max: 2}, Masking: counter("id")},
+		{
+			Name:        "contracts",
+			Parent:      "customers",
+			Cardinality: &model.CardinalityType{Min: 1, Max: 5},
+			References:  []model.ReferenceType{{Field: "customer", Parent: "id"}},
+			Masking:     counter("number"),
+		},
+	}, 42, map[string]model.Cache{})
+	assert.Nil(t, err)
+	assert.Nil(t, process.Open())
+
+	out := &collector{}
+	assert.Nil(t, process.ProcessDictionary(model.NewDictionary(), out))
+	assert.Len(t, out.records, 2)
+	for i, customer := range out.records {
+		assert.Equal(t, i+1, customer.Get("id"))
+		contracts := customer.Get("contracts").([]model.Entry)
+		assert.GreaterOrEqual(t, len(contracts), 1)
+		assert.LessOrEqual(t, len(contracts), 5)
+		for _, contract := range contracts {
+			assert.Equal(t, customer.Get("id"), contract.(model.Dictionary).Get("customer"))
+		}
+	}
+}
+
+func TestProcessShouldWriteStreams(t *testing.T) {
+	model.InjectMaskFactories([]model.MaskFactory{increment.Factory})
+	model.InjectMaskContextFactories([]model.MaskContextFactory{add.Factory})
+	defer model.InjectMaskFactories(nil)
+	defer model.InjectMaskContextFactories(nil)
+
+	file := filepath.Join(t.TempDir(), "events.jsonl")
+	process, err := NewProcess([]model.EntityDefinition{
+		{Name: "contracts", Masking: counter("id")},
+		{
+			Name:        "events",
+			Parent:      "contracts",
+			File:        file,
+			Cardinality: &model.CardinalityType{Distribution: []model.CardinalityWeightType{{Count: 3, Weight: 1}, {Count: 4, Weight: 0}}},
+			References:  []model.ReferenceType{{Field: "contract", Parent: "id"}},
+		},
+	}, 42, map[string]model.Cache{})
+	assert.Nil(t, err)
+	assert.Nil(t, process.Open())
+
+	out := &collector{}
+	assert.Nil(t, process.ProcessDictionary(model.NewDictionary(), out))
+	assert.Nil(t, process.ProcessDictionary(model.NewDictionary(), out))
+	assert.Nil(t, process.Close())
+
+	assert.Len(t, out.records, 2)
+	_, nested := out.records[0].GetValue("events")
+	assert.False(t, nested)
+
+	reader, err := os.Open(file)
+	assert.Nil(t, err)
+	defer reader.Close()
+	source := jsonline.NewSource(reader)
+	assert.Nil(t, source.Open())
+	contracts := []string{}
+	for source.Next() {
+		contracts = append(contracts, fmt.Sprint(source.Value().Get("contract")))
+	}
+	assert.Equal(t, []string{"1", "1", "1", "2", "2", "2"}, contracts)
+}
+
+func TestProcessShouldUseInputAsParent(t *testing.T) {
+	process, err := NewProcess([]model.EntityDefinition{
+		{Name: "orders", References: []model.ReferenceType{{Field: "customer", Parent: "customer.id"}}},
+	}, 42, map[string]model.Cache{})
+	assert.Nil(t, err)
+
+	out := &collector{}
+	input := model.NewDictionary().With("customer", model.NewDictionary().With("id", "C1"))
+	assert.Nil(t, process.ProcessDictionary(input, out))
+	assert.Equal(t, []model.Dictionary{model.NewDictionary().With("customer", "C1")}, out.records)
+}
+
+func TestRestoreShouldReplayCardinalities(t *testing.T) {
+	process, err := NewProcess([]model.EntityDefinition{
+		{Name: "customers", Cardinality: &model.CardinalityType{Min: 0, Max: 10}},
+		{Name: "contracts", Parent: "customers", Cardinality: &model.CardinalityType{Distribution: []model.CardinalityWeightType{{Count: 1, Weight: 1}, {Count: 3, Weight: 1}}}},
+	}, 42, map[string]model.Cache{})
+	assert.Nil(t, err)
+
+	generate := func() []int {
+		out := &collector{}
+		assert.Nil(t, process.ProcessDictionary(model.NewDictionary(), out))
+		counts := []int{len(out.records)}
+		for _, customer := range out.records {
+			counts = append(counts, len(customer.Get("contracts").([]model.Entry)))
+		}
+		return counts
+	}
+
+	generate()
+	state, err := process.State()
+	assert.Nil(t, err)
+	expected := [][]int{generate(), generate(), generate()}
+
+	assert.Nil(t, process.Restore(state))
+	assert.Equal(t, expected, [][]int{generate(), generate(), generate()})
+
+	assert.NotNil(t, process.Restore([]byte(`{"customers":{"cardinality":{"seed":1,"draws":0}}}`)))
+}
+
+func TestRestoreShouldTruncateStreams(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "events.jsonl")
+	entities := []model.EntityDefinition{
+		{Name: "contracts"},
+		{Name: "events", Parent: "contracts", File: file, Cardinality: &model.CardinalityType{Min: 2, Max: 2}},
+	}
+	process, err := NewProcess(entities, 42, map[string]model.Cache{})
+	assert.Nil(t, err)
+	assert.Nil(t, process.Open())
+	assert.Nil(t, process.ProcessDictionary(model.NewDictionary(), &collector{}))
+	state, err := process.State()
+	assert.Nil(t, err)
+	// records written after the checkpoint by the interrupted run
+	assert.Nil(t, process.ProcessDictionary(model.NewDictionary(), &collector{}))
+	assert.Nil(t, process.Close())
+
+	resumed, err := NewProcess(entities, 42, map[string]model.Cache{})
+	assert.Nil(t, err)
+	assert.Nil(t, resumed.Restore(state))
+	assert.Nil(t, resumed.Open())
+	assert.Nil(t, resumed.ProcessDictionary(model.NewDictionary(), &collector{}))
+	assert.Nil(t, resumed.Close())
+
+	content, err := os.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, strings.Count(string(content), "\n"))
+}
+
+func TestEmptyCardinalityShouldGenerateOneRecord(t *testing.T) {
+	process, err := NewProcess([]model.EntityDefinition{{Name: "customers", Cardinality: &model.CardinalityType{}}}, 42, map[string]model.Cache{})
+	assert.Nil(t, err)
+	out := &collector{}
+	assert.Nil(t, process.ProcessDictionary(model.NewDictionary(), out))
+	assert.Len(t, out.records, 1)
+}
+
+func TestNewProcessShouldCheckDefinitions(t *testing.T) {
+	for _, entities := range [][]model.EntityDefinition{
+		{{Name: ""}},
+		{{Name: "a"}, {Name: "a"}},
+		{{Name: "b", Parent: "a"}, {Name: "a"}},
+		{{Name: "a", Cardinality: &model.CardinalityType{Min: 3, Max: 1}}},
+		{{Name: "a", Cardinality: &model.CardinalityType{Distribution: []model.CardinalityWeightType{{Count: 1, Weight: 0}}}}},
+	} {
+		_, err := NewProcess(entities, 42, map[string]model.Cache{})
+		assert.NotNil(t, err, entities)
+	}
+}
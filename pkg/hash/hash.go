@@ -37,8 +37,12 @@ func (hm MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.Ent
 	if e == nil {
 		return e, nil
 	}
+	value, err := model.ToString(e)
+	if err != nil {
+		return nil, err
+	}
 	h := fnv.New32a()
-	_, err := h.Write([]byte(e.(string)))
+	_, err = h.Write([]byte(value))
 	return hm.List[int(h.Sum32())%len(hm.List)], err
 }
 
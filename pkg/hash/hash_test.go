@@ -18,6 +18,7 @@
 package hash
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/cgi-fr/pimo/pkg/model"
@@ -53,3 +54,15 @@ func TestFactoryShouldNotCreateAMaskFromAnEmptyConfig(t *testing.T) {
 	assert.False(t, present, "should be false")
 	assert.Nil(t, err, "error should be nil")
 }
+
+func TestMaskingShouldHashNumbersLikeStrings(t *testing.T) {
+	nameList := []model.Entry{"Michel", "Marc", "Matthias", "Youen", "Alexis"}
+	mask := MaskEngine{nameList}
+
+	fromNumber, err := mask.Mask(json.Number("42"))
+	assert.Nil(t, err)
+	fromString, err := mask.Mask("42")
+	assert.Nil(t, err)
+
+	assert.Equal(t, fromString, fromNumber, "Should be hashed the same way")
+}
@@ -0,0 +1,95 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package iban
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// BICMaskEngine is a mask to generate BIC (ISO 9362), the BIC matches the bank of an IBAN if a field is given
+type BICMaskEngine struct {
+	rand *rand.Rand
+	model.RandState
+	seed      int64
+	country   string
+	ibanField string
+}
+
+// NewBICMask create a BICMaskEngine, ibanField is the name of a field of the context containing an IBAN
+func NewBICMask(country string, ibanField string, seed int64) (BICMaskEngine, error) {
+	country = strings.ToUpper(country)
+	if country == "" {
+		country = "FR"
+	}
+	if _, ok := countries[country]; !ok {
+		return BICMaskEngine{}, fmt.Errorf("country '%s' is not supported", country)
+	}
+	source := model.NewRandSource(seed)
+	// nolint: gosec
+	return BICMaskEngine{rand.New(source), model.NewRandState(source), seed, country, ibanField}, nil
+}
+
+// Mask returns a new BIC, always the same for IBAN of the same bank
+func (me BICMaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.Entry, error) {
+	log.Info().Msg("Mask bic")
+
+	r := me.rand
+	country := me.country
+	if me.ibanField != "" && len(context) > 0 {
+		if value, ok := context[0].GetValue(me.ibanField); ok && value != nil {
+			ibanCountry, bban, ok := Parse(fmt.Sprint(value))
+			if !ok {
+				return nil, fmt.Errorf("'%v' is not a valid IBAN", value)
+			}
+			country = ibanCountry
+			h := fnv.New64a()
+			h.Write([]byte(ibanCountry + bban[:countries[ibanCountry].bank]))
+			// nolint: gosec
+			r = rand.New(rand.NewSource(me.seed + int64(h.Sum64())))
+		}
+	}
+
+	var sb strings.Builder
+	for i := 0; i < 4; i++ {
+		sb.WriteByte(letters[r.Intn(len(letters))])
+	}
+	sb.WriteString(country)
+	for i := 0; i < 2; i++ {
+		sb.WriteByte(alphanumeric[r.Intn(len(alphanumeric))])
+	}
+	return sb.String(), nil
+}
+
+// BICFactory create a mask from a yaml config
+func BICFactory(conf model.Masking, seed int64, caches map[string]model.Cache) (model.MaskEngine, bool, error) {
+	if conf.Mask.BIC != nil {
+		// set differents seeds for differents jsonpath
+		h := fnv.New64a()
+		h.Write([]byte(conf.Selector.Jsonpath))
+		seed += int64(h.Sum64())
+		mask, err := NewBICMask(conf.Mask.BIC.Country, conf.Mask.BIC.IBANField, seed)
+		return mask, true, err
+	}
+	return nil, false, nil
+}
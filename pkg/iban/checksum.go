@@ -0,0 +1,86 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package iban
+
+import "fmt"
+
+// mod97 computes the remainder of the division by 97 of a string of digits and letters,
+// letters are replaced by numbers (A = 10, B = 11, ..., Z = 35) as described in ISO 13616
+func mod97(s string) int {
+	remainder := 0
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			remainder = (remainder*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			remainder = (remainder*100 + int(c-'A') + 10) % 97
+		}
+	}
+	return remainder
+}
+
+// CheckDigits computes the two check digits of an IBAN from the country code and the BBAN
+func CheckDigits(country string, bban string) string {
+	return fmt.Sprintf("%02d", 98-mod97(bban+country+"00"))
+}
+
+// Valid returns true if the IBAN has valid check digits
+func Valid(iban string) bool {
+	if len(iban) < 5 {
+		return false
+	}
+	return mod97(iban[4:]+iban[:4]) == 1
+}
+
+// checksumRIB computes the french RIB key of a BBAN, letters of the account number are replaced by digits
+func checksumRIB(bban string) string {
+	digits := make([]byte, 0, 21)
+	for i := 0; i < 21 && i < len(bban); i++ {
+		c := bban[i]
+		if c >= 'A' && c <= 'Z' {
+			// A-I = 1-9, J-R = 1-9, S-Z = 2-9
+			i := c - 'A'
+			if i >= 18 {
+				i++
+			}
+			c = '1' + i%9
+		}
+		digits = append(digits, c)
+	}
+	bank := atoi(string(digits[0:5]))
+	branch := atoi(string(digits[5:10]))
+	account := atoi(string(digits[10:21]))
+	return fmt.Sprintf("%02d", 97-(89*bank+15*branch+3*account)%97)
+}
+
+// checksumBE computes the belgian check digits of a BBAN
+func checksumBE(bban string) string {
+	key := atoi(bban[0:10]) % 97
+	if key == 0 {
+		key = 97
+	}
+	return fmt.Sprintf("%02d", key)
+}
+
+func atoi(s string) int64 {
+	var n int64
+	for _, c := range s {
+		n = n*10 + int64(c-'0')
+	}
+	return n
+}
@@ -0,0 +1,45 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package iban
+
+// bban describes the structure of the domestic account number of a country (Basic Bank Account Number).
+// Each character of structure gives the kind of the character at the same position :
+// n is a digit, a is an upper case letter, c is a digit or an upper case letter.
+type bban struct {
+	structure string
+	// bank is the length of the bank identifier at the start of the BBAN (bank and branch codes)
+	bank int
+	// check is the length of national check digits at the end of the BBAN, computed by checksum
+	check    int
+	checksum func(string) string
+}
+
+// nolint: gochecknoglobals
+var countries = map[string]bban{
+	"BE": {"nnnnnnnnnnnn", 3, 2, checksumBE},
+	"CH": {"nnnnncccccccccccc", 5, 0, nil},
+	"DE": {"nnnnnnnnnnnnnnnnnn", 8, 0, nil},
+	"ES": {"nnnnnnnnnnnnnnnnnnnn", 8, 0, nil},
+	"FR": {"nnnnnnnnnnccccccccccccc", 10, 2, checksumRIB},
+	"GB": {"aaaannnnnnnnnnnnnn", 10, 0, nil},
+	"IT": {"annnnnnnnnnccccccccccccc", 11, 0, nil},
+	"LU": {"nnncccccccccccccc", 3, 0, nil},
+	"MC": {"nnnnnnnnnnccccccccccccc", 10, 2, checksumRIB},
+	"NL": {"aaaannnnnnnnnn", 4, 0, nil},
+	"PT": {"nnnnnnnnnnnnnnnnnnnnn", 8, 0, nil},
+}
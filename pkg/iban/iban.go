@@ -0,0 +1,196 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package iban
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+
+	"github.com/cgi-fr/pimo/pkg/ff1"
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	digits       = "0123456789"
+	letters      = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	alphanumeric = digits + letters
+)
+
+// MaskEngine is a mask to generate IBAN with valid check digits
+type MaskEngine struct {
+	rand *rand.Rand
+	model.RandState
+	country     string
+	keepCountry bool
+	keepBank    bool
+}
+
+// NewMask create a MaskEngine generating IBAN of the given country
+func NewMask(country string, keepCountry, keepBank bool, seed int64) (MaskEngine, error) {
+	country = strings.ToUpper(country)
+	if country == "" {
+		country = "FR"
+	}
+	if _, ok := countries[country]; !ok {
+		return MaskEngine{}, fmt.Errorf("country '%s' is not supported", country)
+	}
+	source := model.NewRandSource(seed)
+	// nolint: gosec
+	return MaskEngine{rand.New(source), model.NewRandState(source), country, keepCountry, keepBank}, nil
+}
+
+// Mask returns a new IBAN
+func (me MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.Entry, error) {
+	log.Info().Msg("Mask iban")
+
+	country, prefix := me.country, ""
+	if original, ok := e.(string); ok && (me.keepCountry || me.keepBank) {
+		originalCountry, originalBBAN, ok := Parse(original)
+		if ok && (me.keepCountry || originalCountry == me.country) {
+			country = originalCountry
+			if me.keepBank {
+				prefix = originalBBAN[:countries[country].bank]
+			}
+		}
+	}
+
+	structure := countries[country]
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	for _, kind := range structure.structure[len(prefix) : len(structure.structure)-structure.check] {
+		switch kind {
+		case 'n':
+			sb.WriteByte(digits[me.rand.Intn(len(digits))])
+		case 'a':
+			sb.WriteByte(letters[me.rand.Intn(len(letters))])
+		default:
+			sb.WriteByte(alphanumeric[me.rand.Intn(len(alphanumeric))])
+		}
+	}
+
+	return Build(country, sb.String()), nil
+}
+
+// Build returns an IBAN from a country code and a BBAN, national and IBAN check digits are computed
+func Build(country string, bban string) string {
+	structure := countries[country]
+	if structure.check > 0 {
+		bban = bban[:len(structure.structure)-structure.check]
+		bban += structure.checksum(bban)
+	}
+	return country + CheckDigits(country, bban) + bban
+}
+
+// Parse returns the country code and the BBAN of an IBAN, ok is false if the IBAN does not match the structure of its country
+func Parse(iban string) (country string, bban string, ok bool) {
+	iban = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(iban), " ", ""))
+	if len(iban) < 4 {
+		return "", "", false
+	}
+	country, bban = iban[:2], iban[4:]
+	structure, known := countries[country]
+	if !known || len(bban) != len(structure.structure) {
+		return "", "", false
+	}
+	for i, kind := range structure.structure {
+		c := bban[i]
+		switch {
+		case kind == 'n' && !strings.ContainsRune(digits, rune(c)),
+			kind == 'a' && !strings.ContainsRune(letters, rune(c)),
+			kind == 'c' && !strings.ContainsRune(alphanumeric, rune(c)):
+			return "", "", false
+		}
+	}
+	return country, bban, true
+}
+
+// FF1MaskEngine is a mask to encrypt the account part of an IBAN with format preserving encryption,
+// the country, the bank code and the branch code are kept and check digits are recomputed
+type FF1MaskEngine struct {
+	ff1Digits       ff1.MaskEngine
+	ff1Alphanumeric ff1.MaskEngine
+}
+
+// NewFF1Mask create a FF1MaskEngine, parameters are the same as the ff1 mask
+func NewFF1Mask(keyFromEnv string, tweakField string, decrypt bool) FF1MaskEngine {
+	return FF1MaskEngine{
+		ff1.NewMask(keyFromEnv, tweakField, 10, decrypt),
+		ff1.NewMask(keyFromEnv, tweakField, 36, decrypt),
+	}
+}
+
+// Mask returns the encrypted (or decrypted) IBAN
+func (me FF1MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.Entry, error) {
+	if e == nil {
+		log.Warn().Msg("Mask iban - ignored null value")
+		return e, nil
+	}
+
+	log.Info().Msg("Mask iban")
+
+	country, bban, ok := Parse(fmt.Sprint(e))
+	if !ok {
+		return nil, fmt.Errorf("'%v' is not a valid IBAN", e)
+	}
+	if len(context) == 0 {
+		context = []model.Dictionary{model.NewDictionary()}
+	}
+
+	structure := countries[country]
+	account := bban[structure.bank : len(bban)-structure.check]
+	// the radix depends only on the structure, so that decryption uses the radix of encryption
+	kinds := structure.structure[structure.bank : len(bban)-structure.check]
+	var output model.Entry
+	var err error
+	switch {
+	case strings.Trim(kinds, "n") == "":
+		output, err = me.ff1Digits.Mask(account, context...)
+	case strings.Trim(kinds, "c") == "":
+		// ff1 encrypts with lower case letters in radix 36
+		output, err = me.ff1Alphanumeric.Mask(strings.ToLower(account), context...)
+	default:
+		return nil, fmt.Errorf("account number of IBAN '%v' can't be encrypted", e)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return Build(country, bban[:structure.bank]+strings.ToUpper(output.(string))+bban[len(bban)-structure.check:]), nil
+}
+
+// Factory create a mask from a yaml config
+func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (model.MaskEngine, bool, error) {
+	if conf.Mask.IBAN != nil {
+		if conf.Mask.IBAN.FF1 != nil {
+			if conf.Mask.IBAN.FF1.KeyFromEnv == "" {
+				return nil, true, fmt.Errorf("keyFromEnv attribut is not optional")
+			}
+			return NewFF1Mask(conf.Mask.IBAN.FF1.KeyFromEnv, conf.Mask.IBAN.FF1.TweakField, conf.Mask.IBAN.FF1.Decrypt), true, nil
+		}
+		// set differents seeds for differents jsonpath
+		h := fnv.New64a()
+		h.Write([]byte(conf.Selector.Jsonpath))
+		seed += int64(h.Sum64())
+		mask, err := NewMask(conf.Mask.IBAN.Country, conf.Mask.IBAN.KeepCountry, conf.Mask.IBAN.KeepBank, seed)
+		return mask, true, err
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,135 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package iban
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildShouldComputeCheckDigits(t *testing.T) {
+	for _, iban := range []string{
+		"FR1420041010050500013M02606",
+		"DE89370400440532013000",
+		"BE68539007547034",
+		"GB29NWBK60161331926819",
+		"NL91ABNA0417164300",
+	} {
+		assert.True(t, Valid(iban), iban)
+		country, bban, ok := Parse(iban)
+		assert.True(t, ok, iban)
+		assert.Equal(t, iban, Build(country, bban))
+	}
+	assert.False(t, Valid("FR1520041010050500013M02606"))
+}
+
+func TestMaskingShouldGenerateValidIBAN(t *testing.T) {
+	for country := range countries {
+		mask, err := NewMask(country, false, false, 42)
+		assert.Nil(t, err)
+		for i := 0; i < 20; i++ {
+			result, err := mask.Mask("")
+			assert.Nil(t, err)
+			iban := result.(string)
+			assert.True(t, Valid(iban), iban)
+			resultCountry, bban, ok := Parse(iban)
+			assert.True(t, ok, iban)
+			assert.Equal(t, country, resultCountry)
+			assert.Equal(t, Build(country, bban), iban)
+		}
+	}
+}
+
+func TestMaskingShouldKeepCountryAndBank(t *testing.T) {
+	mask, err := NewMask("FR", true, true, 42)
+	assert.Nil(t, err)
+
+	result, err := mask.Mask("DE89 3704 0044 0532 0130 00")
+	assert.Nil(t, err)
+	assert.Regexp(t, `^DE[0-9]{2}37040044[0-9]{10}$`, result)
+	assert.True(t, Valid(result.(string)))
+
+	result, err = mask.Mask("not an iban")
+	assert.Nil(t, err)
+	assert.Regexp(t, `^FR[0-9]{12}`, result)
+}
+
+func TestFF1MaskShouldBeReversible(t *testing.T) {
+	os.Setenv("IBAN_KEY", "70NZ2NWAqk9/A21vBPxqlA==")
+	encrypt := NewFF1Mask("IBAN_KEY", "tweak", false)
+	decrypt := NewFF1Mask("IBAN_KEY", "tweak", true)
+	context := model.NewDictionary().With("tweak", "mytweak")
+
+	for _, original := range []string{"FR1420041010050500013M02606", "FR7630006000011234567890189", "DE89370400440532013000"} {
+		encrypted, err := encrypt.Mask(original, context)
+		assert.Nil(t, err)
+		assert.NotEqual(t, original, encrypted)
+		assert.True(t, Valid(encrypted.(string)))
+		assert.Equal(t, original[:2], encrypted.(string)[:2])
+
+		decrypted, err := decrypt.Mask(encrypted, context)
+		assert.Nil(t, err)
+		assert.Equal(t, original, decrypted)
+	}
+}
+
+func TestFF1MaskShouldChooseRadixFromStructure(t *testing.T) {
+	os.Setenv("IBAN_KEY", "70NZ2NWAqk9/A21vBPxqlA==")
+	encrypt := NewFF1Mask("IBAN_KEY", "tweak", false)
+	context := model.NewDictionary().With("tweak", "mytweak")
+
+	// the french account number is alphanumeric, even if the original one only has digits
+	letters := 0
+	for i := 0; i < 20; i++ {
+		original := Build("FR", fmt.Sprintf("3000600001%011d00", i))
+		encrypted, err := encrypt.Mask(original, context)
+		assert.Nil(t, err)
+		if strings.Trim(encrypted.(string)[14:25], "0123456789") != "" {
+			letters++
+		}
+	}
+	assert.Greater(t, letters, 0)
+}
+
+func TestBICShouldMatchBankOfIBAN(t *testing.T) {
+	mask, err := NewBICMask("FR", "iban", 42)
+	assert.Nil(t, err)
+
+	first, err := mask.Mask("", model.NewDictionary().With("iban", "DE89370400440532013000"))
+	assert.Nil(t, err)
+	second, err := mask.Mask("", model.NewDictionary().With("iban", "DE44370400440532013001"))
+	assert.Nil(t, err)
+	assert.Regexp(t, `^[A-Z]{4}DE[A-Z0-9]{2}$`, first)
+	assert.Equal(t, first, second)
+
+	other, err := mask.Mask("", model.NewDictionary())
+	assert.Nil(t, err)
+	assert.Regexp(t, `^[A-Z]{4}FR[A-Z0-9]{2}$`, other)
+}
+
+func TestFactoryShouldRejectUnknownCountry(t *testing.T) {
+	conf := model.Masking{Mask: model.MaskType{IBAN: &model.IBANType{Country: "ZZ"}}}
+	_, present, err := Factory(conf, 0, nil)
+	assert.True(t, present)
+	assert.NotNil(t, err)
+}
@@ -18,6 +18,8 @@
 package increment
 
 import (
+	"encoding/json"
+
 	"github.com/cgi-fr/pimo/pkg/model"
 	"github.com/rs/zerolog/log"
 )
@@ -49,3 +51,13 @@ func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (mod
 	}
 	return nil, false, nil
 }
+
+// State returns the next value of the increment
+func (incr MaskEngine) State() (json.RawMessage, error) {
+	return json.Marshal(*incr.Value)
+}
+
+// Restore sets the next value of the increment
+func (incr MaskEngine) Restore(state json.RawMessage) error {
+	return json.Unmarshal(state, incr.Value)
+}
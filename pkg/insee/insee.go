@@ -0,0 +1,107 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package insee
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// Departments is the list of french departments codes, overseas departments have 3 characters
+// nolint: gochecknoglobals
+var Departments = departments()
+
+func departments() []string {
+	result := []string{}
+	for i := 1; i <= 95; i++ {
+		switch i {
+		case 20:
+			result = append(result, "2A", "2B")
+		default:
+			result = append(result, fmt.Sprintf("%02d", i))
+		}
+	}
+	return append(result, "971", "972", "973", "974", "976")
+}
+
+// Department returns the department of an INSEE commune code, ok is false if the code is not valid
+func Department(code string) (string, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 5 {
+		return "", false
+	}
+	for _, department := range Departments {
+		if strings.HasPrefix(code, department) && strings.Trim(code[len(department):], "0123456789") == "" {
+			return department, true
+		}
+	}
+	return "", false
+}
+
+// Commune returns a random INSEE commune code in the department (a random department if empty)
+func Commune(r *rand.Rand, department string) string {
+	if department == "" {
+		department = Departments[r.Intn(len(Departments))]
+	}
+	if len(department) == 3 {
+		return fmt.Sprintf("%s%02d", department, 1+r.Intn(34))
+	}
+	return fmt.Sprintf("%s%03d", department, 1+r.Intn(699))
+}
+
+// MaskEngine is a mask to generate INSEE commune codes
+type MaskEngine struct {
+	rand *rand.Rand
+	model.RandState
+	keepDepartment bool
+}
+
+// NewMask create a MaskEngine
+func NewMask(keepDepartment bool, seed int64) MaskEngine {
+	source := model.NewRandSource(seed)
+	// nolint: gosec
+	return MaskEngine{rand.New(source), model.NewRandState(source), keepDepartment}
+}
+
+// Mask returns a new INSEE commune code
+func (me MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.Entry, error) {
+	log.Info().Msg("Mask insee")
+
+	department := ""
+	if original, ok := e.(string); ok && me.keepDepartment {
+		department, _ = Department(original)
+	}
+	return Commune(me.rand, department), nil
+}
+
+// Factory create a mask from a yaml config
+func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (model.MaskEngine, bool, error) {
+	if conf.Mask.INSEE != nil {
+		// set differents seeds for differents jsonpath
+		h := fnv.New64a()
+		h.Write([]byte(conf.Selector.Jsonpath))
+		seed += int64(h.Sum64())
+		return NewMask(conf.Mask.INSEE.KeepDepartment, seed), true, nil
+	}
+	return nil, false, nil
+}
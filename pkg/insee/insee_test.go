@@ -0,0 +1,49 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+package insee
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDepartment(t *testing.T) {
+	for code, department := range map[string]string{"75056": "75", "2A004": "2A", "97411": "974", "01001": "01"} {
+		result, ok := Department(code)
+		assert.True(t, ok, code)
+		assert.Equal(t, department, result, code)
+	}
+	for _, code := range []string{"20001", "97501", "7505", "99100"} {
+		_, ok := Department(code)
+		assert.False(t, ok, code)
+	}
+}
+
+func TestMaskingShouldKeepDepartment(t *testing.T) {
+	mask := NewMask(true, 42)
+	for i := 0; i < 20; i++ {
+		result, err := mask.Mask("97411")
+		assert.Nil(t, err)
+		assert.Regexp(t, `^974[0-9]{2}$`, result)
+
+		result, err = mask.Mask("unknown")
+		assert.Nil(t, err)
+		_, ok := Department(result.(string))
+		assert.True(t, ok)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package ip
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+)
+
+// KeySize is the size of a Crypto-PAn key : 16 bytes of AES key and 16 bytes to build the padding
+const KeySize = 32
+
+// CryptoPAn is a prefix-preserving anonymization of addresses (Xu et al., 2002) :
+// two addresses sharing a prefix of n bits are anonymized to two addresses sharing a prefix of n bits
+type CryptoPAn struct {
+	block cipher.Block
+	pad   [aes.BlockSize]byte
+}
+
+// NewCryptoPAn create a CryptoPAn from a key of 32 bytes
+func NewCryptoPAn(key []byte) (*CryptoPAn, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must have %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key[:16])
+	if err != nil {
+		return nil, err
+	}
+	c := &CryptoPAn{block: block}
+	block.Encrypt(c.pad[:], key[16:])
+	return c, nil
+}
+
+// Anonymize returns the anonymized address, address is 4 bytes (IPv4) or 16 bytes (IPv6) long
+func (c *CryptoPAn) Anonymize(address []byte) []byte {
+	bits := len(address) * 8
+	result := make([]byte, len(address))
+	var input, output [aes.BlockSize]byte
+
+	for pos := 0; pos < bits; pos++ {
+		// the first pos bits of the address, followed by the bits of the pad
+		input = c.pad
+		for i := 0; i < pos/8; i++ {
+			input[i] = address[i]
+		}
+		if pos%8 != 0 {
+			mask := byte(0xff << (8 - pos%8))
+			input[pos/8] = (address[pos/8] & mask) | (c.pad[pos/8] &^ mask)
+		}
+		c.block.Encrypt(output[:], input[:])
+		// the first bit of the output is the flip of the bit at pos
+		result[pos/8] |= (output[0] >> 7) << (7 - pos%8)
+	}
+
+	for i := range result {
+		result[i] ^= address[i]
+	}
+	return result
+}
@@ -0,0 +1,96 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package ip
+
+import (
+	b64 "encoding/base64"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// MaskEngine is a mask to anonymize IPv4 and IPv6 addresses while preserving prefixes
+type MaskEngine struct {
+	cryptopan *CryptoPAn
+}
+
+// NewMask create a MaskEngine from a key of 32 bytes
+func NewMask(key []byte) (MaskEngine, error) {
+	cryptopan, err := NewCryptoPAn(key)
+	return MaskEngine{cryptopan}, err
+}
+
+// Mask returns the anonymized address, CIDR notation is accepted (e.g. 192.168.0.0/16)
+func (me MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.Entry, error) {
+	if e == nil {
+		log.Warn().Msg("Mask ip - ignored null value")
+		return e, nil
+	}
+
+	log.Info().Msg("Mask ip")
+
+	value := fmt.Sprint(e)
+	if address, network, err := net.ParseCIDR(value); err == nil {
+		ones, _ := network.Mask.Size()
+		anonymized := me.anonymize(address)
+		return anonymized.Mask(network.Mask).String() + "/" + strconv.Itoa(ones), nil
+	}
+
+	address := net.ParseIP(value)
+	if address == nil {
+		return nil, fmt.Errorf("'%s' is not a valid IP address", value)
+	}
+	return me.anonymize(address).String(), nil
+}
+
+func (me MaskEngine) anonymize(address net.IP) net.IP {
+	if ipv4 := address.To4(); ipv4 != nil {
+		return net.IP(me.cryptopan.Anonymize(ipv4))
+	}
+	return net.IP(me.cryptopan.Anonymize(address.To16()))
+}
+
+// Key returns the key from the environment variable (base64 encoded), the key is never derived from the configuration
+// because Crypto-PAn is invertible with the key
+func Key(keyFromEnv string) ([]byte, error) {
+	if keyFromEnv == "" {
+		return nil, fmt.Errorf("keyFromEnv attribut is not optional")
+	}
+	envKey := os.Getenv(keyFromEnv)
+	if envKey == "" {
+		return nil, fmt.Errorf("Environment variable named '%s' should be defined", keyFromEnv)
+	}
+	return b64.StdEncoding.DecodeString(envKey)
+}
+
+// Factory create a mask from a yaml config
+func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (model.MaskEngine, bool, error) {
+	if conf.Mask.IP != nil {
+		key, err := Key(conf.Mask.IP.KeyFromEnv)
+		if err != nil {
+			return nil, true, err
+		}
+		mask, err := NewMask(key)
+		return mask, true, err
+	}
+	return nil, false, nil
+}
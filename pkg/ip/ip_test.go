@@ -0,0 +1,102 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+package ip
+
+import (
+	"net"
+	"os"
+	"testing"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// key and addresses from the sample of the reference implementation of Crypto-PAn
+// nolint: gochecknoglobals
+var referenceKey = []byte{
+	21, 34, 23, 141, 51, 164, 207, 128, 19, 10, 91, 22, 73, 144, 125, 16,
+	216, 152, 143, 131, 121, 121, 101, 39, 98, 87, 76, 45, 42, 132, 34, 2,
+}
+
+func TestCryptoPAnReferenceSample(t *testing.T) {
+	mask, err := NewMask(referenceKey)
+	assert.Nil(t, err)
+
+	for original, expected := range map[string]string{
+		"128.11.68.132":   "135.242.180.132",
+		"129.118.74.4":    "134.136.186.123",
+		"130.132.252.244": "133.68.164.234",
+		"141.223.7.43":    "141.167.8.160",
+	} {
+		result, err := mask.Mask(original)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, result, original)
+	}
+}
+
+func TestMaskingShouldPreservePrefixes(t *testing.T) {
+	mask, err := NewMask(referenceKey)
+	assert.Nil(t, err)
+
+	first, err := mask.Mask("192.168.10.1")
+	assert.Nil(t, err)
+	second, err := mask.Mask("192.168.10.200")
+	assert.Nil(t, err)
+	other, err := mask.Mask("192.168.11.1")
+	assert.Nil(t, err)
+	network, err := mask.Mask("192.168.10.0/24")
+	assert.Nil(t, err)
+
+	_, subnet, err := net.ParseCIDR(network.(string))
+	assert.Nil(t, err)
+	assert.True(t, subnet.Contains(net.ParseIP(first.(string))))
+	assert.True(t, subnet.Contains(net.ParseIP(second.(string))))
+	assert.False(t, subnet.Contains(net.ParseIP(other.(string))))
+
+	v6first, err := mask.Mask("2001:db8:85a3::8a2e:370:7334")
+	assert.Nil(t, err)
+	v6second, err := mask.Mask("2001:db8:85a3::1")
+	assert.Nil(t, err)
+	assert.Equal(t, net.ParseIP(v6first.(string)).Mask(net.CIDRMask(48, 128)), net.ParseIP(v6second.(string)).Mask(net.CIDRMask(48, 128)))
+
+	_, err = mask.Mask("not an ip")
+	assert.NotNil(t, err)
+}
+
+func TestFactoryShouldReadKeyFromEnv(t *testing.T) {
+	conf := model.Masking{Mask: model.MaskType{IP: &model.IPType{KeyFromEnv: "IP_KEY_UNDEFINED"}}}
+	_, present, err := Factory(conf, 0, nil)
+	assert.True(t, present)
+	assert.NotNil(t, err)
+
+	os.Setenv("IP_KEY", "FSIXjTOkz4ATClsWSZB9ENiYj4N5eWUnYldMLSqEIgI=")
+	conf = model.Masking{Mask: model.MaskType{IP: &model.IPType{KeyFromEnv: "IP_KEY"}}}
+	mask, present, err := Factory(conf, 42, nil)
+	assert.True(t, present)
+	assert.Nil(t, err)
+	result, err := mask.Mask("128.11.68.132")
+	assert.Nil(t, err)
+	assert.Equal(t, "135.242.180.132", result)
+}
+
+func TestFactoryShouldNotDeriveKeyFromSeed(t *testing.T) {
+	conf := model.Masking{Mask: model.MaskType{IP: &model.IPType{}}}
+	mask, present, err := Factory(conf, 42, nil)
+	assert.True(t, present)
+	assert.Nil(t, mask)
+	assert.Equal(t, "keyFromEnv attribut is not optional", err.Error())
+}
@@ -48,7 +48,10 @@ func (l MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.Entr
 	factor := 2
 	sum := 0
 	n := len(l.Universe)
-	input := e.(string)
+	input, err := model.ToString(e)
+	if err != nil {
+		return nil, err
+	}
 
 	// Starting from the right and working leftwards is easier since
 	// the initial "factor" will always be "2".
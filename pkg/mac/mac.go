@@ -0,0 +1,106 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package mac
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+const hexDigits = "0123456789abcdef"
+
+// MaskEngine is a mask to generate MAC addresses in the format of the original address
+type MaskEngine struct {
+	rand *rand.Rand
+	model.RandState
+	keepOUI bool
+}
+
+// NewMask create a MaskEngine, the Organizationally Unique Identifier (3 first bytes) is kept if keepOUI is true
+func NewMask(keepOUI bool, seed int64) MaskEngine {
+	source := model.NewRandSource(seed)
+	// nolint: gosec
+	return MaskEngine{rand.New(source), model.NewRandState(source), keepOUI}
+}
+
+// Mask returns a new MAC address, separators (:, - or .) and case of the original address are kept
+func (me MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.Entry, error) {
+	if e == nil {
+		log.Warn().Msg("Mask mac - ignored null value")
+		return e, nil
+	}
+
+	log.Info().Msg("Mask mac")
+
+	original := fmt.Sprint(e)
+	digits := 0
+	for _, c := range original {
+		if strings.ContainsRune(hexDigits, c) || strings.ContainsRune("ABCDEF", c) {
+			digits++
+		} else if !strings.ContainsRune(":-.", c) {
+			return nil, fmt.Errorf("'%s' is not a valid MAC address", original)
+		}
+	}
+	if digits != 12 {
+		return nil, fmt.Errorf("'%s' is not a valid MAC address", original)
+	}
+
+	upper := strings.ToLower(original) != original
+	var sb strings.Builder
+	position := 0
+	for _, c := range original {
+		if strings.ContainsRune(":-.", c) {
+			sb.WriteRune(c)
+			continue
+		}
+		var digit byte
+		switch {
+		case me.keepOUI && position < 6:
+			digit = byte(c)
+		case position == 1:
+			// unicast and locally administered address
+			digit = hexDigits[(me.rand.Intn(16)&^1)|2]
+		default:
+			digit = hexDigits[me.rand.Intn(16)]
+		}
+		if upper {
+			sb.WriteString(strings.ToUpper(string(digit)))
+		} else {
+			sb.WriteString(strings.ToLower(string(digit)))
+		}
+		position++
+	}
+	return sb.String(), nil
+}
+
+// Factory create a mask from a yaml config
+func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (model.MaskEngine, bool, error) {
+	if conf.Mask.MAC != nil {
+		// set differents seeds for differents jsonpath
+		h := fnv.New64a()
+		h.Write([]byte(conf.Selector.Jsonpath))
+		seed += int64(h.Sum64())
+		return NewMask(conf.Mask.MAC.KeepOUI, seed), true, nil
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,53 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+package mac
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskingShouldKeepFormat(t *testing.T) {
+	mask := NewMask(false, 42)
+
+	for _, tt := range []struct {
+		original string
+		expected string
+	}{
+		{"00:1A:2B:3C:4D:5E", `^[0-9A-F][26AE](:[0-9A-F]{2}){5}$`},
+		{"00-1a-2b-3c-4d-5e", `^[0-9a-f][26ae](-[0-9a-f]{2}){5}$`},
+		{"001a.2b3c.4d5e", `^[0-9a-f][26ae][0-9a-f]{2}(\.[0-9a-f]{4}){2}$`},
+	} {
+		result, err := mask.Mask(tt.original)
+		assert.Nil(t, err)
+		assert.Regexp(t, tt.expected, result)
+	}
+
+	_, err := mask.Mask("00:1A:2B")
+	assert.NotNil(t, err)
+	_, err = mask.Mask("00:1A:2B:3C:4D:ZZ")
+	assert.NotNil(t, err)
+}
+
+func TestMaskingShouldKeepOUI(t *testing.T) {
+	mask := NewMask(true, 42)
+
+	result, err := mask.Mask("00:1A:2B:3C:4D:5E")
+	assert.Nil(t, err)
+	assert.Regexp(t, `^00:1A:2B(:[0-9A-F]{2}){3}$`, result)
+}
@@ -0,0 +1,82 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package maskingdata
+
+var NameBEM = []Weighted{
+	{"Noah", 100}, {"Arthur", 96}, {"Louis", 90}, {"Jules", 88}, {"Adam", 86},
+	{"Liam", 84}, {"Lucas", 82}, {"Victor", 78}, {"Gabriel", 76}, {"Mohamed", 74},
+	{"Finn", 70}, {"Leon", 66}, {"Nathan", 64}, {"Vic", 62}, {"Lars", 58},
+	{"Mathis", 56}, {"Emiel", 54}, {"Leo", 52}, {"Kobe", 50}, {"Hugo", 50},
+	{"Thomas", 48}, {"Maxime", 46}, {"Milan", 44}, {"Wout", 42}, {"Matthias", 40},
+	{"Jan", 38}, {"Luc", 36}, {"Marc", 36}, {"Dirk", 34}, {"Patrick", 34},
+	{"Philippe", 33}, {"Jean", 32}, {"Pieter", 32}, {"Bart", 31}, {"Koen", 30},
+	{"Geert", 29}, {"Michel", 28}, {"Eric", 28}, {"Willy", 26}, {"Jozef", 25},
+}
+
+var NameBEF = []Weighted{
+	{"Olivia", 100}, {"Emma", 96}, {"Louise", 94}, {"Mila", 88}, {"Alice", 86},
+	{"Juliette", 80}, {"Lina", 78}, {"Elena", 74}, {"Nora", 72}, {"Ella", 70},
+	{"Marie", 68}, {"Camille", 66}, {"Anna", 64}, {"Lucie", 60}, {"Julie", 58},
+	{"Sofia", 56}, {"Léa", 54}, {"Fien", 52}, {"Noor", 50}, {"Lotte", 48},
+	{"Zoé", 46}, {"Sarah", 44}, {"Laura", 42}, {"Charlotte", 40}, {"Lisa", 40},
+	{"Els", 36}, {"Ann", 36}, {"An", 35}, {"Sofie", 34}, {"Katrien", 32},
+	{"Nathalie", 32}, {"Isabelle", 31}, {"Martine", 30}, {"Christine", 30}, {"Maria", 29},
+	{"Nicole", 28}, {"Veerle", 26}, {"Inge", 25}, {"Chantal", 24}, {"Anne", 24},
+}
+
+var SurnameBE = []Weighted{
+	{"Peeters", 100}, {"Janssens", 92}, {"Maes", 80}, {"Jacobs", 64}, {"Mertens", 60},
+	{"Willems", 58}, {"Claes", 56}, {"Goossens", 54}, {"Wouters", 52}, {"De Smet", 50},
+	{"Dubois", 48}, {"Lambert", 46}, {"Dupont", 44}, {"Martin", 42}, {"Hermans", 40},
+	{"Aerts", 40}, {"Vermeulen", 38}, {"Pauwels", 37}, {"Van den Broeck", 36}, {"Michiels", 35},
+	{"Desmet", 34}, {"Leroy", 33}, {"Simon", 32}, {"Stevens", 31}, {"Smets", 30},
+	{"Renard", 30}, {"Laurent", 29}, {"Van Damme", 28}, {"Cools", 27}, {"Dumont", 27},
+	{"Lemmens", 26}, {"Verstraete", 26}, {"Martens", 25}, {"Bogaert", 25}, {"Claeys", 24},
+	{"Mathieu", 24}, {"Leclercq", 23}, {"Declercq", 23}, {"François", 22}, {"Hendrickx", 22},
+}
+
+var TownBE = []Town{
+	{"2000", "Antwerpen", 530000}, {"9000", "Gent", 265000}, {"6000", "Charleroi", 202000},
+	{"4000", "Liège", 197000}, {"1000", "Bruxelles", 185000}, {"1030", "Schaerbeek", 133000},
+	{"1070", "Anderlecht", 121000}, {"8000", "Brugge", 118000}, {"5000", "Namur", 111000},
+	{"3000", "Leuven", 102000}, {"7000", "Mons", 95000}, {"9300", "Aalst", 88000},
+	{"2800", "Mechelen", 87000}, {"1050", "Ixelles", 87000}, {"7100", "La Louvière", 81000},
+	{"3500", "Hasselt", 79000}, {"9100", "Sint-Niklaas", 78000}, {"8500", "Kortrijk", 77000},
+	{"8400", "Oostende", 71000}, {"7500", "Tournai", 69000}, {"3600", "Genk", 66000},
+	{"4100", "Seraing", 64000}, {"8800", "Roeselare", 63000}, {"7700", "Mouscron", 59000},
+	{"4800", "Verviers", 55000}, {"9120", "Beveren", 48000}, {"3580", "Beringen", 47000},
+	{"9200", "Dendermonde", 46000}, {"2300", "Turnhout", 45000}, {"1800", "Vilvoorde", 45000},
+	{"1700", "Dilbeek", 43000}, {"1300", "Wavre", 34000}, {"6700", "Arlon", 30000},
+}
+
+var StreetBE = []Weighted{
+	{"Kerkstraat", 100}, {"Stationsstraat", 85}, {"Rue de la Station", 60}, {"Nieuwstraat", 55}, {"Molenstraat", 52},
+	{"Rue de l'Église", 50}, {"Dorpsstraat", 48}, {"Schoolstraat", 45}, {"Grand-Rue", 40}, {"Kapelstraat", 40},
+	{"Rue du Moulin", 38}, {"Beekstraat", 36}, {"Kasteelstraat", 35}, {"Rue de Bruxelles", 34}, {"Rue Haute", 30},
+	{"Veldstraat", 30}, {"Lindenlaan", 28}, {"Rue des Écoles", 28}, {"Heirbaan", 27}, {"Rue du Centre", 26},
+	{"Brusselsesteenweg", 26}, {"Hoogstraat", 25}, {"Rue de la Gare", 25}, {"Rue Neuve", 24}, {"Langstraat", 22},
+	{"Rue du Château", 21}, {"Kouter", 20}, {"Place communale", 18}, {"Avenue Louise", 10}, {"Meir", 8},
+}
+
+var CompanyBE = []Weighted{
+	{"Peeters & Zonen BV", 1}, {"Janssens Bouw NV", 1}, {"Dubois Construction SRL", 1}, {"Maes Logistics NV", 1},
+	{"Vlaamse Handelsmaatschappij NV", 1}, {"Wallonie Énergie SA", 1}, {"Mertens Consulting BV", 1}, {"Brabant Immo SRL", 1},
+	{"Lambert & Fils SRL", 1}, {"Schelde Transport NV", 1}, {"Ardennes Bois SA", 1}, {"Goossens Elektro BV", 1},
+	{"Meuse Ingénierie SA", 1}, {"De Smet Accountants BV", 1}, {"Flanders Software NV", 1}, {"Dupont Boulangerie SRL", 1},
+	{"Kempen Tuinbouw CV", 1}, {"Liégeoise de Services SA", 1}, {"Wouters Interieur BV", 1}, {"Benelux Trading NV", 1},
+}
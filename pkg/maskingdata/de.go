@@ -0,0 +1,87 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package maskingdata
+
+var NameDEM = []Weighted{
+	{"Ben", 100}, {"Paul", 95}, {"Leon", 92}, {"Finn", 90}, {"Elias", 88},
+	{"Jonas", 86}, {"Luis", 84}, {"Noah", 83}, {"Felix", 80}, {"Lukas", 78},
+	{"Maximilian", 76}, {"Henry", 70}, {"Luca", 69}, {"Emil", 68}, {"Anton", 64},
+	{"Theo", 63}, {"Jakob", 60}, {"Leo", 59}, {"Liam", 58}, {"Moritz", 55},
+	{"Alexander", 54}, {"Julian", 52}, {"Niklas", 50}, {"Tim", 48}, {"David", 47},
+	{"Jan", 46}, {"Philipp", 44}, {"Tom", 42}, {"Simon", 40}, {"Fabian", 38},
+	{"Matteo", 37}, {"Oskar", 36}, {"Samuel", 35}, {"Daniel", 34}, {"Michael", 33},
+	{"Thomas", 32}, {"Stefan", 30}, {"Andreas", 30}, {"Markus", 29}, {"Christian", 29},
+	{"Sebastian", 28}, {"Peter", 28}, {"Wolfgang", 27}, {"Klaus", 26}, {"Jürgen", 25},
+	{"Uwe", 24}, {"Frank", 24}, {"Dieter", 22}, {"Hans", 22}, {"Günter", 20},
+}
+
+var NameDEF = []Weighted{
+	{"Mia", 100}, {"Emma", 98}, {"Hannah", 95}, {"Sofia", 92}, {"Emilia", 90},
+	{"Lina", 88}, {"Mila", 85}, {"Ella", 82}, {"Lea", 80}, {"Clara", 78},
+	{"Marie", 76}, {"Leni", 74}, {"Anna", 72}, {"Luisa", 70}, {"Lena", 68},
+	{"Frieda", 65}, {"Ida", 62}, {"Johanna", 60}, {"Lara", 58}, {"Laura", 56},
+	{"Sophie", 55}, {"Charlotte", 54}, {"Greta", 52}, {"Amelie", 50}, {"Lilly", 48},
+	{"Paula", 46}, {"Mathilda", 45}, {"Nele", 42}, {"Maja", 40}, {"Julia", 40},
+	{"Sarah", 38}, {"Katharina", 36}, {"Lisa", 36}, {"Sabine", 34}, {"Andrea", 33},
+	{"Susanne", 32}, {"Petra", 31}, {"Jana", 30}, {"Claudia", 30}, {"Franziska", 29},
+	{"Monika", 29}, {"Ursula", 28}, {"Stefanie", 28}, {"Renate", 27}, {"Nicole", 27},
+	{"Karin", 26}, {"Melanie", 26}, {"Birgit", 25}, {"Helga", 22}, {"Ingrid", 21},
+}
+
+var SurnameDE = []Weighted{
+	{"Müller", 256}, {"Schmidt", 190}, {"Schneider", 115}, {"Fischer", 98}, {"Weber", 86},
+	{"Meyer", 84}, {"Wagner", 80}, {"Becker", 74}, {"Schulz", 73}, {"Hoffmann", 72},
+	{"Schäfer", 60}, {"Koch", 59}, {"Bauer", 58}, {"Richter", 57}, {"Klein", 56},
+	{"Wolf", 54}, {"Schröder", 53}, {"Neumann", 52}, {"Schwarz", 50}, {"Zimmermann", 49},
+	{"Braun", 47}, {"Krüger", 46}, {"Hofmann", 45}, {"Hartmann", 44}, {"Lange", 43},
+	{"Schmitt", 42}, {"Werner", 41}, {"Schmitz", 40}, {"Krause", 39}, {"Meier", 38},
+	{"Lehmann", 37}, {"Schmid", 36}, {"Schulze", 35}, {"Maier", 34}, {"Köhler", 33},
+	{"Herrmann", 32}, {"König", 31}, {"Walter", 30}, {"Mayer", 29}, {"Huber", 28},
+}
+
+var TownDE = []Town{
+	{"10115", "Berlin", 3645000}, {"20095", "Hamburg", 1841000}, {"80331", "München", 1472000},
+	{"50667", "Köln", 1086000}, {"60311", "Frankfurt am Main", 753000}, {"70173", "Stuttgart", 635000},
+	{"40213", "Düsseldorf", 619000}, {"44135", "Dortmund", 588000}, {"04109", "Leipzig", 587000},
+	{"45127", "Essen", 582000}, {"28195", "Bremen", 567000}, {"01067", "Dresden", 556000},
+	{"30159", "Hannover", 536000}, {"90402", "Nürnberg", 518000}, {"47051", "Duisburg", 498000},
+	{"44787", "Bochum", 365000}, {"42103", "Wuppertal", 355000}, {"33602", "Bielefeld", 333000},
+	{"53111", "Bonn", 327000}, {"48143", "Münster", 315000}, {"68159", "Mannheim", 310000},
+	{"76133", "Karlsruhe", 308000}, {"86150", "Augsburg", 296000}, {"65183", "Wiesbaden", 278000},
+	{"41061", "Mönchengladbach", 261000}, {"45879", "Gelsenkirchen", 260000}, {"38100", "Braunschweig", 249000},
+	{"52062", "Aachen", 248000}, {"24103", "Kiel", 246000}, {"09111", "Chemnitz", 246000},
+	{"79098", "Freiburg im Breisgau", 230000}, {"55116", "Mainz", 217000}, {"99084", "Erfurt", 213000},
+	{"18055", "Rostock", 209000}, {"69117", "Heidelberg", 160000}, {"93047", "Regensburg", 153000},
+}
+
+var StreetDE = []Weighted{
+	{"Hauptstraße", 100}, {"Schulstraße", 80}, {"Gartenstraße", 70}, {"Dorfstraße", 68}, {"Bahnhofstraße", 66},
+	{"Bergstraße", 60}, {"Birkenweg", 55}, {"Lindenstraße", 52}, {"Kirchstraße", 50}, {"Waldstraße", 48},
+	{"Ringstraße", 45}, {"Schillerstraße", 42}, {"Goethestraße", 41}, {"Wiesenweg", 40}, {"Feldstraße", 39},
+	{"Mühlenweg", 36}, {"Amselweg", 35}, {"Jahnstraße", 33}, {"Buchenweg", 32}, {"Rosenstraße", 31},
+	{"Friedhofstraße", 30}, {"Blumenstraße", 29}, {"Eichenweg", 28}, {"Mozartstraße", 26}, {"Am Sportplatz", 25},
+	{"Poststraße", 24}, {"Talstraße", 23}, {"Kastanienallee", 20}, {"Marktplatz", 19}, {"Industriestraße", 18},
+}
+
+var CompanyDE = []Weighted{
+	{"Müller & Söhne GmbH", 1}, {"Schneider Logistik GmbH", 1}, {"Nordwind Energie AG", 1}, {"Bergmann Maschinenbau GmbH", 1},
+	{"Weber Bau KG", 1}, {"Rheinland Consulting GmbH", 1}, {"Fischer Elektrotechnik e.K.", 1}, {"Alpen Software GmbH", 1},
+	{"Hansa Handelsgesellschaft mbH", 1}, {"Becker Immobilien GmbH", 1}, {"Schwarzwald Holzbau GmbH", 1}, {"Spree Digital GmbH", 1},
+	{"Hoffmann Transport GmbH & Co. KG", 1}, {"Elbe Versicherungsmakler GmbH", 1}, {"Krüger Medizintechnik AG", 1}, {"Sonnenfeld Agrar eG", 1},
+	{"Lindner Steuerberatung GmbH", 1}, {"Main Finanzberatung AG", 1}, {"Wagner Metallbau GmbH", 1}, {"Isar Gastronomie GmbH", 1},
+}
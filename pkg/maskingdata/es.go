@@ -0,0 +1,81 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package maskingdata
+
+var NameESM = []Weighted{
+	{"Antonio", 100}, {"Manuel", 82}, {"José", 78}, {"Francisco", 64}, {"David", 58},
+	{"Juan", 55}, {"Javier", 52}, {"Daniel", 50}, {"José Antonio", 48}, {"Carlos", 45},
+	{"Alejandro", 44}, {"Francisco Javier", 42}, {"Jesús", 40}, {"Miguel", 38}, {"Rafael", 36},
+	{"Pablo", 34}, {"Pedro", 33}, {"Sergio", 33}, {"Ángel", 32}, {"Fernando", 30},
+	{"Jorge", 30}, {"Luis", 29}, {"Alberto", 27}, {"Adrián", 27}, {"Álvaro", 26},
+	{"Diego", 25}, {"Raúl", 24}, {"Hugo", 24}, {"Enrique", 22}, {"Mario", 22},
+	{"Iván", 21}, {"Rubén", 20}, {"Óscar", 20}, {"Martín", 20}, {"Andrés", 19},
+	{"Marcos", 18}, {"Lucas", 18}, {"Mateo", 17}, {"Ramón", 16}, {"Leo", 15},
+}
+
+var NameESF = []Weighted{
+	{"María Carmen", 100}, {"María", 95}, {"Carmen", 85}, {"Ana María", 60}, {"Laura", 58},
+	{"María Pilar", 55}, {"Josefa", 50}, {"Isabel", 50}, {"María Dolores", 48}, {"Ana", 47},
+	{"Cristina", 45}, {"Marta", 44}, {"Lucía", 43}, {"Sara", 40}, {"Paula", 40},
+	{"Elena", 38}, {"Pilar", 36}, {"Dolores", 35}, {"Raquel", 33}, {"Rosa", 32},
+	{"Manuela", 30}, {"Mercedes", 29}, {"Antonia", 29}, {"Julia", 27}, {"Alba", 26},
+	{"Irene", 25}, {"Silvia", 25}, {"Beatriz", 24}, {"Patricia", 24}, {"Andrea", 23},
+	{"Nuria", 22}, {"Rocío", 22}, {"Sofía", 22}, {"Martina", 21}, {"Claudia", 20},
+	{"Teresa", 20}, {"Valeria", 18}, {"Noelia", 17}, {"Montserrat", 17}, {"Inmaculada", 16},
+}
+
+var SurnameES = []Weighted{
+	{"García", 146}, {"Rodríguez", 93}, {"González", 92}, {"Fernández", 91}, {"López", 87},
+	{"Martínez", 83}, {"Sánchez", 81}, {"Pérez", 78}, {"Gómez", 50}, {"Martín", 49},
+	{"Jiménez", 39}, {"Ruiz", 36}, {"Hernández", 36}, {"Díaz", 35}, {"Moreno", 34},
+	{"Muñoz", 29}, {"Álvarez", 29}, {"Romero", 24}, {"Alonso", 21}, {"Gutiérrez", 20},
+	{"Navarro", 19}, {"Torres", 19}, {"Domínguez", 18}, {"Vázquez", 17}, {"Ramos", 17},
+	{"Gil", 16}, {"Ramírez", 16}, {"Serrano", 16}, {"Blanco", 15}, {"Molina", 15},
+	{"Morales", 14}, {"Suárez", 14}, {"Ortega", 14}, {"Delgado", 13}, {"Castro", 13},
+	{"Ortiz", 13}, {"Rubio", 13}, {"Marín", 12}, {"Sanz", 12}, {"Núñez", 12},
+}
+
+var TownES = []Town{
+	{"28001", "Madrid", 3305000}, {"08001", "Barcelona", 1636000}, {"46001", "Valencia", 792000},
+	{"41001", "Sevilla", 684000}, {"50001", "Zaragoza", 675000}, {"29001", "Málaga", 579000},
+	{"30001", "Murcia", 460000}, {"07001", "Palma", 419000}, {"35001", "Las Palmas de Gran Canaria", 379000},
+	{"48001", "Bilbao", 346000}, {"03001", "Alicante", 337000}, {"14001", "Córdoba", 322000},
+	{"47001", "Valladolid", 298000}, {"36201", "Vigo", 296000}, {"33201", "Gijón", 268000},
+	{"08901", "L'Hospitalet de Llobregat", 265000}, {"01001", "Vitoria-Gasteiz", 253000}, {"15001", "A Coruña", 245000},
+	{"03201", "Elche", 234000}, {"18001", "Granada", 230000}, {"33001", "Oviedo", 219000},
+	{"38001", "Santa Cruz de Tenerife", 208000}, {"31001", "Pamplona", 203000}, {"04001", "Almería", 200000},
+	{"20001", "San Sebastián", 187000}, {"09001", "Burgos", 174000}, {"39001", "Santander", 172000},
+	{"37001", "Salamanca", 143000}, {"11001", "Cádiz", 114000}, {"45001", "Toledo", 85000},
+}
+
+var StreetES = []Weighted{
+	{"Calle Mayor", 100}, {"Calle Real", 80}, {"Plaza Mayor", 60}, {"Calle de la Iglesia", 58}, {"Calle Nueva", 55},
+	{"Avenida de la Constitución", 50}, {"Calle del Sol", 45}, {"Calle San Juan", 44}, {"Calle Cervantes", 40}, {"Calle de la Paz", 38},
+	{"Calle Alta", 36}, {"Calle del Carmen", 35}, {"Calle de la Fuente", 34}, {"Calle Sevilla", 30}, {"Avenida de Andalucía", 28},
+	{"Calle Castilla", 27}, {"Calle de Santiago", 26}, {"Calle Colón", 25}, {"Calle Goya", 24}, {"Calle del Pilar", 23},
+	{"Calle Federico García Lorca", 22}, {"Calle Antonio Machado", 22}, {"Calle de la Rosa", 20}, {"Avenida de España", 20}, {"Calle del Río", 19},
+	{"Calle de las Flores", 18}, {"Calle de la Estación", 18}, {"Calle Miguel Hernández", 17}, {"Paseo de la Castellana", 10}, {"Rambla de Catalunya", 8},
+}
+
+var CompanyES = []Weighted{
+	{"Construcciones García S.L.", 1}, {"Transportes Martínez S.A.", 1}, {"Soluciones Digitales Ibéricas S.L.", 1}, {"Gestoría López y Asociados S.L.", 1},
+	{"Distribuciones Levante S.A.", 1}, {"Hermanos Fernández S.L.", 1}, {"Inmobiliaria Costa del Sol S.L.", 1}, {"Consultoría Castellana S.A.", 1},
+	{"Alimentación Sánchez e Hijos S.L.", 1}, {"Energías Renovables del Norte S.A.", 1}, {"Talleres Navarro S.L.", 1}, {"Viajes Mediterráneo S.L.", 1},
+	{"Seguros Atlántico S.A.", 1}, {"Bodegas Ribera Alta S.L.", 1}, {"Clínica Dental Ruiz S.L.P.", 1}, {"Logística Ebro S.L.", 1},
+	{"Tecnologías Alhambra S.L.", 1}, {"Muebles Gómez S.A.", 1}, {"Asesores Cantábrico S.L.", 1}, {"Textil Moreno S.L.", 1},
+}
@@ -0,0 +1,44 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package maskingdata
+
+var PostcodeTownFR = []Town{
+	{"75001", "Paris", 2161000}, {"13001", "Marseille", 870000}, {"69001", "Lyon", 516000},
+	{"31000", "Toulouse", 479000}, {"06000", "Nice", 342000}, {"44000", "Nantes", 309000},
+	{"34000", "Montpellier", 285000}, {"67000", "Strasbourg", 280000}, {"33000", "Bordeaux", 257000},
+	{"59000", "Lille", 232000}, {"35000", "Rennes", 216000}, {"51100", "Reims", 182000},
+	{"42000", "Saint-Étienne", 173000}, {"83000", "Toulon", 171000}, {"76600", "Le Havre", 170000},
+	{"38000", "Grenoble", 158000}, {"21000", "Dijon", 156000}, {"49000", "Angers", 154000},
+	{"30000", "Nîmes", 149000}, {"69100", "Villeurbanne", 149000}, {"63000", "Clermont-Ferrand", 143000},
+	{"72000", "Le Mans", 142000}, {"13090", "Aix-en-Provence", 142000}, {"29200", "Brest", 139000},
+	{"37000", "Tours", 136000}, {"80000", "Amiens", 133000}, {"87000", "Limoges", 131000},
+	{"74000", "Annecy", 128000}, {"66000", "Perpignan", 119000}, {"57000", "Metz", 116000},
+	{"25000", "Besançon", 116000}, {"45000", "Orléans", 114000}, {"76000", "Rouen", 110000},
+	{"68100", "Mulhouse", 108000}, {"14000", "Caen", 105000}, {"54000", "Nancy", 104000},
+	{"17000", "La Rochelle", 77000}, {"64000", "Pau", 75000}, {"56100", "Lorient", 57000},
+	{"13200", "Arles", 51000}, {"65000", "Tarbes", 41000}, {"13300", "Salon-de-Provence", 45000},
+}
+
+var StreetFR = []Weighted{
+	{"Rue de l'Église", 100}, {"Place de l'Église", 80}, {"Grande Rue", 70}, {"Rue du Moulin", 65}, {"Place de la Mairie", 62},
+	{"Rue du Château", 55}, {"Rue des Écoles", 50}, {"Rue de la Gare", 48}, {"Rue de la Mairie", 46}, {"Rue Principale", 45},
+	{"Rue du Stade", 40}, {"Rue de la Fontaine", 38}, {"Rue Pasteur", 36}, {"Rue Victor Hugo", 35}, {"Rue des Jardins", 34},
+	{"Rue Jean Jaurès", 33}, {"Rue de la Paix", 31}, {"Impasse des Lilas", 30}, {"Rue de la République", 30}, {"Avenue du Général de Gaulle", 28},
+	{"Rue des Tilleuls", 27}, {"Rue du Général Leclerc", 26}, {"Rue de la Poste", 25}, {"Rue Charles de Gaulle", 24}, {"Rue des Prés", 23},
+	{"Rue des Vignes", 22}, {"Chemin des Vignes", 21}, {"Rue du Lavoir", 20}, {"Allée des Peupliers", 18}, {"Boulevard Gambetta", 15},
+}
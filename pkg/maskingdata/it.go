@@ -0,0 +1,81 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package maskingdata
+
+var NameITM = []Weighted{
+	{"Francesco", 100}, {"Alessandro", 92}, {"Andrea", 88}, {"Lorenzo", 86}, {"Matteo", 84},
+	{"Leonardo", 82}, {"Gabriele", 75}, {"Mattia", 72}, {"Luca", 70}, {"Riccardo", 68},
+	{"Davide", 66}, {"Tommaso", 64}, {"Giuseppe", 62}, {"Marco", 60}, {"Antonio", 58},
+	{"Giovanni", 56}, {"Federico", 54}, {"Edoardo", 52}, {"Simone", 50}, {"Diego", 48},
+	{"Pietro", 46}, {"Filippo", 44}, {"Christian", 40}, {"Stefano", 40}, {"Roberto", 38},
+	{"Paolo", 37}, {"Giorgio", 35}, {"Mario", 34}, {"Salvatore", 33}, {"Vincenzo", 32},
+	{"Luigi", 30}, {"Fabio", 29}, {"Daniele", 28}, {"Michele", 27}, {"Alberto", 26},
+	{"Emanuele", 25}, {"Nicola", 24}, {"Massimo", 23}, {"Claudio", 22}, {"Franco", 20},
+}
+
+var NameITF = []Weighted{
+	{"Sofia", 100}, {"Giulia", 94}, {"Aurora", 90}, {"Alice", 86}, {"Ginevra", 82},
+	{"Emma", 80}, {"Giorgia", 77}, {"Greta", 74}, {"Beatrice", 70}, {"Anna", 68},
+	{"Chiara", 66}, {"Martina", 64}, {"Vittoria", 60}, {"Sara", 58}, {"Francesca", 56},
+	{"Maria", 55}, {"Elena", 52}, {"Ludovica", 50}, {"Matilde", 48}, {"Camilla", 46},
+	{"Nicole", 44}, {"Alessia", 42}, {"Gaia", 40}, {"Noemi", 38}, {"Rebecca", 36},
+	{"Valentina", 36}, {"Federica", 34}, {"Elisa", 33}, {"Paola", 32}, {"Laura", 31},
+	{"Silvia", 30}, {"Roberta", 29}, {"Cristina", 28}, {"Giovanna", 27}, {"Rosa", 26},
+	{"Angela", 25}, {"Lucia", 24}, {"Teresa", 23}, {"Barbara", 22}, {"Marta", 21},
+}
+
+var SurnameIT = []Weighted{
+	{"Rossi", 100}, {"Russo", 80}, {"Ferrari", 76}, {"Esposito", 72}, {"Bianchi", 62},
+	{"Romano", 60}, {"Colombo", 56}, {"Ricci", 52}, {"Marino", 50}, {"Greco", 48},
+	{"Bruno", 46}, {"Gallo", 45}, {"Conti", 44}, {"De Luca", 43}, {"Mancini", 42},
+	{"Costa", 41}, {"Giordano", 40}, {"Rizzo", 39}, {"Lombardi", 38}, {"Moretti", 37},
+	{"Barbieri", 35}, {"Fontana", 34}, {"Santoro", 33}, {"Mariani", 32}, {"Rinaldi", 31},
+	{"Caruso", 30}, {"Ferrara", 29}, {"Galli", 28}, {"Martini", 28}, {"Leone", 27},
+	{"Longo", 27}, {"Gentile", 26}, {"Martinelli", 26}, {"Vitale", 25}, {"Lombardo", 25},
+	{"Serra", 24}, {"Coppola", 24}, {"De Santis", 23}, {"D'Angelo", 23}, {"Marchetti", 22},
+}
+
+var TownIT = []Town{
+	{"00118", "Roma", 2873000}, {"20121", "Milano", 1352000}, {"80121", "Napoli", 959000},
+	{"10121", "Torino", 870000}, {"90121", "Palermo", 668000}, {"16121", "Genova", 583000},
+	{"40121", "Bologna", 389000}, {"50121", "Firenze", 382000}, {"70121", "Bari", 323000},
+	{"95121", "Catania", 311000}, {"30121", "Venezia", 261000}, {"37121", "Verona", 259000},
+	{"98121", "Messina", 234000}, {"35121", "Padova", 211000}, {"34121", "Trieste", 204000},
+	{"74121", "Taranto", 198000}, {"25121", "Brescia", 197000}, {"43121", "Parma", 195000},
+	{"59100", "Prato", 195000}, {"41121", "Modena", 185000}, {"89121", "Reggio Calabria", 180000},
+	{"42121", "Reggio Emilia", 171000}, {"06121", "Perugia", 166000}, {"57121", "Livorno", 158000},
+	{"48121", "Ravenna", 158000}, {"09121", "Cagliari", 154000}, {"71121", "Foggia", 151000},
+	{"47921", "Rimini", 150000}, {"84121", "Salerno", 133000}, {"44121", "Ferrara", 132000},
+}
+
+var StreetIT = []Weighted{
+	{"Via Roma", 100}, {"Via Garibaldi", 90}, {"Via Giuseppe Mazzini", 75}, {"Via Camillo Cavour", 60}, {"Via Dante Alighieri", 58},
+	{"Via Vittorio Emanuele II", 55}, {"Via Giacomo Matteotti", 52}, {"Via Umberto I", 48}, {"Via Giuseppe Verdi", 45}, {"Via Guglielmo Marconi", 44},
+	{"Via XX Settembre", 42}, {"Piazza della Repubblica", 40}, {"Via Aldo Moro", 38}, {"Via Alessandro Manzoni", 36}, {"Via Antonio Gramsci", 34},
+	{"Via IV Novembre", 33}, {"Via della Chiesa", 30}, {"Via San Francesco", 28}, {"Via della Libertà", 27}, {"Corso Italia", 26},
+	{"Via Nazionale", 25}, {"Via Piave", 24}, {"Via Galileo Galilei", 24}, {"Via Trieste", 23}, {"Via Leonardo da Vinci", 23},
+	{"Via Trento", 22}, {"Via Cesare Battisti", 21}, {"Viale Europa", 20}, {"Via Fiume", 18}, {"Piazza del Popolo", 15},
+}
+
+var CompanyIT = []Weighted{
+	{"Costruzioni Rossi S.r.l.", 1}, {"Trasporti Esposito S.p.A.", 1}, {"Ferrari Impianti S.r.l.", 1}, {"Studio Bianchi & Associati", 1},
+	{"Alimentari Romano S.n.c.", 1}, {"Colombo Informatica S.r.l.", 1}, {"Tessitura Lombarda S.p.A.", 1}, {"Ricci Arredamenti S.r.l.", 1},
+	{"Mediterranea Servizi S.r.l.", 1}, {"Officine Meccaniche Greco S.p.A.", 1}, {"Gallo Logistica S.r.l.", 1}, {"Conti Immobiliare S.r.l.", 1},
+	{"Adriatica Assicurazioni S.p.A.", 1}, {"Cantine Toscane S.r.l.", 1}, {"Energia Verde Italia S.r.l.", 1}, {"Marino Consulting S.r.l.", 1},
+	{"Farmacia Moretti S.a.s.", 1}, {"Edilizia Barbieri S.r.l.", 1}, {"Fontana Design S.r.l.", 1}, {"Gruppo Santoro S.p.A.", 1},
+}
@@ -17,6 +17,12 @@
 
 package maskingdata
 
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+)
+
 var MapData = map[string][]string{
 	"nameFR":    append(NameFRM, NameFRF...),
 	"nameFRM":   NameFRM,
@@ -27,3 +33,126 @@
 	"surnameFR": SurnameFR,
 	"townFR":    TownFR,
 }
+
+// WeightedData contains the datasets with a frequency for each value, values are also available in MapData
+var WeightedData = map[string][]Weighted{}
+
+// Weighted is a value of a dataset with its frequency
+type Weighted struct {
+	Value  string
+	Weight uint
+}
+
+// Town is a town of a dataset, the population is used as frequency
+type Town struct {
+	Postcode   string
+	Name       string
+	Population uint
+}
+
+// Address is the reference dataset of a country to generate coherent addresses
+type Address struct {
+	Country string
+	Towns   []Town
+	Streets []Weighted
+}
+
+// AddressData contains the reference datasets of addresses by country code
+var AddressData = map[string]Address{}
+
+type locale struct {
+	code    string
+	country string
+	male    []Weighted
+	female  []Weighted
+	surname []Weighted
+	town    []Town
+	street  []Weighted
+	company []Weighted
+	// postcodeLast is true when the postcode is written after the town name
+	postcodeLast bool
+}
+
+func init() {
+	locales := []locale{
+		{code: "DE", country: "Germany", male: NameDEM, female: NameDEF, surname: SurnameDE, town: TownDE, street: StreetDE, company: CompanyDE},
+		{code: "ES", country: "Spain", male: NameESM, female: NameESF, surname: SurnameES, town: TownES, street: StreetES, company: CompanyES},
+		{code: "IT", country: "Italy", male: NameITM, female: NameITF, surname: SurnameIT, town: TownIT, street: StreetIT, company: CompanyIT},
+		{code: "BE", country: "Belgium", male: NameBEM, female: NameBEF, surname: SurnameBE, town: TownBE, street: StreetBE, company: CompanyBE},
+		{code: "UK", country: "United Kingdom", male: NameUKM, female: NameUKF, surname: SurnameUK, town: TownUK, street: StreetUK, company: CompanyUK, postcodeLast: true},
+	}
+	for _, l := range locales {
+		names := make([]Weighted, 0, len(l.male)+len(l.female))
+		names = append(names, l.male...)
+		names = append(names, l.female...)
+		register("name"+l.code, names)
+		register("name"+l.code+"M", l.male)
+		register("name"+l.code+"F", l.female)
+		register("surname"+l.code, l.surname)
+		register("street"+l.code, l.street)
+		register("company"+l.code, l.company)
+
+		towns := make([]Weighted, len(l.town))
+		postcodes := make([]Weighted, len(l.town))
+		addresses := make([]Weighted, len(l.town))
+		for i, t := range l.town {
+			towns[i] = Weighted{t.Name, t.Population}
+			postcodes[i] = Weighted{t.Postcode, t.Population}
+			if l.postcodeLast {
+				addresses[i] = Weighted{t.Name + " " + t.Postcode, t.Population}
+			} else {
+				addresses[i] = Weighted{t.Postcode + " " + t.Name, t.Population}
+			}
+		}
+		register("town"+l.code, towns)
+		register("postcode"+l.code, postcodes)
+		register("postcodeTown"+l.code, addresses)
+		AddressData[l.code] = Address{l.country, l.town, l.street}
+	}
+
+	// townFR is kept as the historical list of town names
+	postcodes := make([]Weighted, len(PostcodeTownFR))
+	addresses := make([]Weighted, len(PostcodeTownFR))
+	for i, t := range PostcodeTownFR {
+		postcodes[i] = Weighted{t.Postcode, t.Population}
+		addresses[i] = Weighted{t.Postcode + " " + t.Name, t.Population}
+	}
+	register("postcodeFR", postcodes)
+	register("postcodeTownFR", addresses)
+	register("streetFR", StreetFR)
+	AddressData["FR"] = Address{"France", PostcodeTownFR, StreetFR}
+}
+
+func register(name string, data []Weighted) {
+	values := make([]string, len(data))
+	for i, w := range data {
+		values[i] = w.Value
+	}
+	WeightedData[name] = data
+	MapData[name] = values
+}
+
+// WeightedLines returns a dataset as CSV rows value,weight, values of a dataset without frequencies have a weight of 1
+func WeightedLines(name string) ([]string, bool) {
+	data, ok := WeightedData[name]
+	if !ok {
+		values, ok := MapData[name]
+		if !ok {
+			return nil, false
+		}
+		data = make([]Weighted, len(values))
+		for i, v := range values {
+			data[i] = Weighted{v, 1}
+		}
+	}
+
+	lines := make([]string, len(data))
+	for i, w := range data {
+		var buffer bytes.Buffer
+		writer := csv.NewWriter(&buffer)
+		_ = writer.Write([]string{w.Value, strconv.FormatUint(uint64(w.Weight), 10)})
+		writer.Flush()
+		lines[i] = string(bytes.TrimRight(buffer.Bytes(), "\n"))
+	}
+	return lines, true
+}
@@ -0,0 +1,50 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package maskingdata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocaleDatasetsShouldBeRegistered(t *testing.T) {
+	for _, code := range []string{"DE", "ES", "IT", "BE", "UK"} {
+		for _, prefix := range []string{"name", "surname", "town", "postcode", "postcodeTown", "street", "company"} {
+			name := prefix + code
+			assert.NotEmpty(t, MapData[name], name)
+			assert.Len(t, WeightedData[name], len(MapData[name]), name)
+			for _, w := range WeightedData[name] {
+				assert.NotZero(t, w.Weight, name)
+			}
+		}
+		assert.Len(t, MapData["name"+code], len(MapData["name"+code+"M"])+len(MapData["name"+code+"F"]))
+	}
+}
+
+func TestWeightedLinesShouldQuoteValues(t *testing.T) {
+	WeightedData["test"] = []Weighted{{"Smith, Jones", 2}}
+	defer delete(WeightedData, "test")
+
+	lines, ok := WeightedLines("test")
+	assert.True(t, ok)
+	assert.Equal(t, []string{`"Smith, Jones",2`}, lines)
+
+	_, ok = WeightedLines("unknown")
+	assert.False(t, ok)
+}
@@ -0,0 +1,81 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package maskingdata
+
+var NameUKM = []Weighted{
+	{"Oliver", 100}, {"George", 96}, {"Harry", 92}, {"Noah", 90}, {"Jack", 86},
+	{"Leo", 84}, {"Arthur", 82}, {"Muhammad", 80}, {"Oscar", 78}, {"Charlie", 76},
+	{"Jacob", 72}, {"Thomas", 70}, {"Henry", 68}, {"William", 66}, {"Alfie", 64},
+	{"Joshua", 60}, {"Theo", 58}, {"Freddie", 56}, {"James", 55}, {"Archie", 54},
+	{"Ethan", 50}, {"Isaac", 48}, {"Alexander", 46}, {"Joseph", 44}, {"Edward", 42},
+	{"Samuel", 41}, {"Max", 40}, {"David", 40}, {"Daniel", 38}, {"John", 38},
+	{"Logan", 36}, {"Lucas", 36}, {"Michael", 36}, {"Paul", 34}, {"Andrew", 33},
+	{"Mark", 32}, {"Richard", 31}, {"Peter", 30}, {"Christopher", 30}, {"Stephen", 29},
+}
+
+var NameUKF = []Weighted{
+	{"Olivia", 100}, {"Amelia", 96}, {"Isla", 92}, {"Ava", 88}, {"Mia", 86},
+	{"Ivy", 82}, {"Lily", 80}, {"Isabella", 78}, {"Rosie", 74}, {"Sophia", 72},
+	{"Grace", 70}, {"Freya", 68}, {"Willow", 66}, {"Florence", 64}, {"Emily", 62},
+	{"Ella", 60}, {"Poppy", 58}, {"Evie", 56}, {"Elsie", 54}, {"Charlotte", 52},
+	{"Evelyn", 50}, {"Sienna", 48}, {"Sofia", 46}, {"Daisy", 44}, {"Phoebe", 42},
+	{"Sarah", 40}, {"Emma", 40}, {"Jessica", 38}, {"Hannah", 37}, {"Lucy", 36},
+	{"Sophie", 36}, {"Laura", 34}, {"Rebecca", 33}, {"Helen", 32}, {"Claire", 31},
+	{"Susan", 30}, {"Elizabeth", 30}, {"Margaret", 28}, {"Victoria", 28}, {"Jennifer", 27},
+}
+
+var SurnameUK = []Weighted{
+	{"Smith", 100}, {"Jones", 76}, {"Williams", 60}, {"Taylor", 52}, {"Brown", 51},
+	{"Davies", 48}, {"Evans", 39}, {"Wilson", 36}, {"Thomas", 35}, {"Johnson", 34},
+	{"Roberts", 33}, {"Robinson", 28}, {"Thompson", 28}, {"Wright", 28}, {"Walker", 27},
+	{"White", 26}, {"Edwards", 26}, {"Hughes", 26}, {"Green", 25}, {"Hall", 25},
+	{"Lewis", 25}, {"Harris", 24}, {"Clarke", 24}, {"Patel", 24}, {"Jackson", 23},
+	{"Wood", 22}, {"Turner", 21}, {"Martin", 21}, {"Cooper", 20}, {"Hill", 20},
+	{"Ward", 20}, {"Morris", 19}, {"Moore", 19}, {"Clark", 19}, {"Lee", 18},
+	{"King", 18}, {"Baker", 18}, {"Harrison", 17}, {"Morgan", 17}, {"Allen", 16},
+}
+
+var TownUK = []Town{
+	{"SW1A 1AA", "London", 8982000}, {"B1 1AA", "Birmingham", 1141000}, {"LS1 1UR", "Leeds", 793000},
+	{"G1 1DU", "Glasgow", 635000}, {"S1 2HE", "Sheffield", 584000}, {"M1 1AE", "Manchester", 553000},
+	{"EH1 1YZ", "Edinburgh", 527000}, {"L1 8JQ", "Liverpool", 498000}, {"BS1 4DJ", "Bristol", 463000},
+	{"CV1 5RR", "Coventry", 371000}, {"CF10 1EP", "Cardiff", 364000}, {"LE1 5YA", "Leicester", 355000},
+	{"BD1 1HY", "Bradford", 349000}, {"BT1 5GS", "Belfast", 343000}, {"NG1 5DT", "Nottingham", 321000},
+	{"NE1 7RU", "Newcastle upon Tyne", 300000}, {"PL1 2AA", "Plymouth", 262000}, {"HU1 2AA", "Kingston upon Hull", 260000},
+	{"DE1 2FS", "Derby", 257000}, {"ST4 1RN", "Stoke-on-Trent", 256000}, {"SO14 7LY", "Southampton", 253000},
+	{"SA1 3SN", "Swansea", 246000}, {"PO1 2AL", "Portsmouth", 238000}, {"BN1 1UG", "Brighton", 229000},
+	{"YO1 7HH", "York", 210000}, {"AB10 1AB", "Aberdeen", 198000}, {"RG1 2LU", "Reading", 174000},
+	{"OX1 1BX", "Oxford", 152000}, {"CB2 3QJ", "Cambridge", 145000}, {"NR2 1NH", "Norwich", 142000},
+}
+
+var StreetUK = []Weighted{
+	{"High Street", 100}, {"Station Road", 90}, {"Main Street", 60}, {"Park Road", 55}, {"Church Road", 54},
+	{"Church Street", 52}, {"London Road", 48}, {"Victoria Road", 46}, {"Green Lane", 44}, {"Manor Road", 42},
+	{"Church Lane", 40}, {"Park Avenue", 38}, {"The Avenue", 36}, {"The Crescent", 34}, {"Queens Road", 33},
+	{"New Road", 32}, {"Grange Road", 31}, {"Kings Road", 30}, {"Kingsway", 28}, {"Windsor Road", 27},
+	{"Highfield Road", 26}, {"Mill Lane", 26}, {"Alexander Road", 24}, {"York Road", 24}, {"St. John's Road", 22},
+	{"Main Road", 22}, {"Broadway", 20}, {"King Street", 20}, {"The Green", 19}, {"Springfield Road", 18},
+}
+
+var CompanyUK = []Weighted{
+	{"Smith & Sons Ltd", 1}, {"Thames Valley Consulting Ltd", 1}, {"Northern Logistics plc", 1}, {"Jones Building Services Ltd", 1},
+	{"Albion Software Ltd", 1}, {"Pennine Engineering Ltd", 1}, {"Williams & Taylor LLP", 1}, {"Highland Foods Ltd", 1},
+	{"Brown Property Holdings Ltd", 1}, {"Severn Energy plc", 1}, {"Cotswold Interiors Ltd", 1}, {"Evans Haulage Ltd", 1},
+	{"Mersey Marine Services Ltd", 1}, {"Wilson Accountancy LLP", 1}, {"Clydeside Manufacturing Ltd", 1}, {"Kentish Gardens Ltd", 1},
+	{"Baker Street Analytics Ltd", 1}, {"Wessex Insurance Brokers Ltd", 1}, {"Lakeland Outdoor Ltd", 1}, {"Thompson Retail Group plc", 1},
+}
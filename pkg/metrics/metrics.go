@@ -0,0 +1,313 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/cgi-fr/pimo/pkg/statistics"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	counterType = "counter"
+	gaugeType   = "gauge"
+)
+
+// Metric is a single value exposed in the Prometheus text format, it is safe for concurrent use
+type Metric struct {
+	name   string
+	labels string
+	bits   uint64
+}
+
+// Set replaces the value of the metric
+func (m *Metric) Set(value float64) {
+	atomic.StoreUint64(&m.bits, math.Float64bits(value))
+}
+
+// Add increments the value of the metric by delta
+func (m *Metric) Add(delta float64) {
+	for {
+		old := atomic.LoadUint64(&m.bits)
+		next := math.Float64bits(math.Float64frombits(old) + delta)
+		if atomic.CompareAndSwapUint64(&m.bits, old, next) {
+			return
+		}
+	}
+}
+
+// Inc increments the value of the metric by one
+func (m *Metric) Inc() {
+	m.Add(1)
+}
+
+// Value returns the current value of the metric
+func (m *Metric) Value() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&m.bits))
+}
+
+type family struct {
+	name    string
+	help    string
+	kind    string
+	metrics []*Metric
+}
+
+// Registry holds metric families and renders them in the Prometheus text format
+type Registry struct {
+	mu       sync.RWMutex
+	families []*family
+	byName   map[string]*family
+}
+
+func NewRegistry() *Registry {
+	return &Registry{byName: map[string]*family{}}
+}
+
+// Counter registers a new counter, labels are given as key/value pairs
+func (r *Registry) Counter(name, help string, labels ...string) *Metric {
+	return r.register(name, help, counterType, labels)
+}
+
+// Gauge registers a new gauge, labels are given as key/value pairs
+func (r *Registry) Gauge(name, help string, labels ...string) *Metric {
+	return r.register(name, help, gaugeType, labels)
+}
+
+func (r *Registry) register(name, help, kind string, labels []string) *Metric {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.byName[name]
+	if !ok {
+		f = &family{name: name, help: help, kind: kind}
+		r.byName[name] = f
+		r.families = append(r.families, f)
+	}
+
+	metric := &Metric{name: name, labels: formatLabels(labels)}
+	f.metrics = append(f.metrics, metric)
+	return metric
+}
+
+func formatLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	pairs := []string{}
+	for i := 0; i+1 < len(labels); i += 2 {
+		value := strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(labels[i+1])
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", labels[i], value))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// WriteTo writes all registered metrics in the Prometheus text format
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var sb strings.Builder
+	for _, f := range r.families {
+		fmt.Fprintf(&sb, "# HELP %s %s\n", f.name, f.help)
+		fmt.Fprintf(&sb, "# TYPE %s %s\n", f.name, f.kind)
+		metrics := append([]*Metric{}, f.metrics...)
+		sort.SliceStable(metrics, func(i, j int) bool { return metrics[i].labels < metrics[j].labels })
+		for _, m := range metrics {
+			fmt.Fprintf(&sb, "%s%s %s\n", m.name, m.labels, strconv.FormatFloat(m.Value(), 'g', -1, 64))
+		}
+	}
+
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if _, err := r.WriteTo(w); err != nil {
+		log.Warn().AnErr("error", err).Msg("Unable to write metrics")
+	}
+}
+
+// Exporter tracks the progress of a masking pipeline
+type Exporter struct {
+	registry     *Registry
+	start        time.Time
+	linesRead    *Metric
+	linesWritten *Metric
+	ignoredPaths *Metric
+	skipLines    *Metric
+	skipFields   *Metric
+	invalidLines *Metric
+	evictions    *Metric
+	throughput   *Metric
+	elapsed      *Metric
+	caches       map[string]model.Cache
+	cacheSizes   map[string]*Metric
+	saturations  map[string]*Metric
+	lastRefresh  time.Time
+}
+
+func NewExporter() *Exporter {
+	registry := NewRegistry()
+	return &Exporter{
+		registry:     registry,
+		start:        time.Now(),
+		linesRead:    registry.Counter("pimo_input_lines_total", "Number of lines read from the input."),
+		linesWritten: registry.Counter("pimo_output_lines_total", "Number of lines written to the output."),
+		ignoredPaths: registry.Counter("pimo_ignored_paths_total", "Number of paths not found in data."),
+		skipLines:    registry.Counter("pimo_skipped_lines_total", "Number of lines skipped because of an error (flag --skip-line-on-error)."),
+		skipFields:   registry.Counter("pimo_skipped_fields_total", "Number of fields skipped because of an error (flag --skip-field-on-error)."),
+		invalidLines: registry.Counter("pimo_invalid_lines_total", "Number of lines not valid against the output schema (flag --validate-output)."),
+		evictions:    registry.Counter("pimo_cache_evictions_total", "Number of cache entries evicted (maxEntries or ttl of caches)."),
+		throughput:   registry.Gauge("pimo_throughput_lines_per_second", "Average number of lines written per second since the start of the pipeline."),
+		elapsed:      registry.Gauge("pimo_elapsed_seconds", "Time elapsed since the start of the pipeline."),
+		caches:       map[string]model.Cache{},
+		cacheSizes:   map[string]*Metric{},
+		saturations:  map[string]*Metric{},
+	}
+}
+
+// Registry returns the metrics registry of the exporter
+func (e *Exporter) Registry() *Registry {
+	return e.registry
+}
+
+// WatchCaches registers a size gauge for each cache, and a saturation gauge for bounded unique caches
+func (e *Exporter) WatchCaches(caches map[string]model.Cache) {
+	for name, cache := range caches {
+		e.caches[name] = cache
+		e.cacheSizes[name] = e.registry.Gauge("pimo_cache_entries", "Number of entries in a cache.", "cache", name)
+		if _, ok := cache.(model.Saturable); ok {
+			e.saturations[name] = e.registry.Gauge("pimo_cache_bloom_saturation", "Number of values in the bloom filter of a bounded unique cache divided by its capacity.", "cache", name)
+		}
+	}
+	e.refresh()
+}
+
+// Reset sets the start time of throughput measure to now
+func (e *Exporter) Reset() {
+	e.start = time.Now()
+}
+
+// InputProcess returns a processor counting the lines read from the input
+func (e *Exporter) InputProcess() model.Processor {
+	return inputProcess{e}
+}
+
+// Sink wraps a sink to count the lines written to the output
+func (e *Exporter) Sink(sink model.SinkProcess) model.SinkProcess {
+	return outputSink{e, sink}
+}
+
+// Serve starts an HTTP listener exposing metrics on /metrics
+func (e *Exporter) Serve(addr string) (net.Addr, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e.registry)
+	// nolint: gosec
+	server := &http.Server{Handler: mux}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Warn().AnErr("error", err).Msg("Metrics listener stopped")
+		}
+	}()
+	return listener.Addr(), nil
+}
+
+// refreshPeriod limits how often values owned by the pipeline are copied to metrics
+const refreshPeriod = 100 * time.Millisecond
+
+// tick refreshes metrics if the last refresh is older than refreshPeriod
+func (e *Exporter) tick() {
+	if time.Since(e.lastRefresh) >= refreshPeriod {
+		e.refresh()
+	}
+}
+
+// refresh updates values that are owned by the pipeline goroutine, it must be called from this goroutine
+func (e *Exporter) refresh() {
+	e.lastRefresh = time.Now()
+	stats := statistics.Compute()
+	e.ignoredPaths.Set(float64(stats.GetIgnoredPathsCount()))
+	e.skipLines.Set(float64(stats.GetIgnoredLinesCount()))
+	e.skipFields.Set(float64(stats.GetIgnoredFieldsCount()))
+	e.invalidLines.Set(float64(stats.GetInvalidLinesCount()))
+	e.evictions.Set(float64(stats.GetEvictedCacheEntriesCount()))
+
+	for name, cache := range e.caches {
+		e.cacheSizes[name].Set(float64(cache.Len()))
+		if saturable, ok := cache.(model.Saturable); ok {
+			e.saturations[name].Set(saturable.Saturation())
+		}
+	}
+
+	elapsed := time.Since(e.start).Seconds()
+	e.elapsed.Set(elapsed)
+	if elapsed > 0 {
+		e.throughput.Set(e.linesWritten.Value() / elapsed)
+	}
+}
+
+type inputProcess struct {
+	exporter *Exporter
+}
+
+func (p inputProcess) Open() error {
+	return nil
+}
+
+func (p inputProcess) ProcessDictionary(dictionary model.Dictionary, out model.Collector) error {
+	p.exporter.linesRead.Inc()
+	p.exporter.tick()
+	out.Collect(dictionary)
+	return nil
+}
+
+type outputSink struct {
+	exporter *Exporter
+	sink     model.SinkProcess
+}
+
+func (s outputSink) Open() error {
+	return s.sink.Open()
+}
+
+func (s outputSink) ProcessDictionary(dictionary model.Dictionary) error {
+	err := s.sink.ProcessDictionary(dictionary)
+	if err == nil {
+		s.exporter.linesWritten.Inc()
+	}
+	s.exporter.tick()
+	return err
+}
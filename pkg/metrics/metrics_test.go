@@ -0,0 +1,99 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/cgi-fr/pimo/pkg/statistics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryShouldWritePrometheusTextFormat(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.Counter("test_total", "A test counter.")
+	gauge := registry.Gauge("test_size", "A test gauge.", "name", "b")
+	other := registry.Gauge("test_size", "A test gauge.", "name", "a")
+
+	counter.Inc()
+	counter.Add(2)
+	gauge.Set(1.5)
+	other.Set(4)
+
+	var output bytes.Buffer
+	_, err := registry.WriteTo(&output)
+	assert.Nil(t, err)
+
+	expected := `# HELP test_total A test counter.
+# TYPE test_total counter
+test_total 3
+# HELP test_size A test gauge.
+# TYPE test_size gauge
+test_size{name="a"} 4
+test_size{name="b"} 1.5
+`
+	assert.Equal(t, expected, output.String())
+}
+
+func TestExporterShouldCountLinesAndCacheEntries(t *testing.T) {
+	statistics.Reset()
+	cache := model.NewMemCache()
+	exporter := NewExporter()
+	exporter.WatchCaches(map[string]model.Cache{"names": cache})
+
+	input := []model.Dictionary{
+		model.NewDictionary().With("name", "Alice"),
+		model.NewDictionary().With("name", "Bob"),
+	}
+	mapper := func(dictionary model.Dictionary) (model.Dictionary, error) {
+		cache.Put(dictionary.Get("name"), "masked")
+		return dictionary, nil
+	}
+
+	var result []model.Dictionary
+	err := model.NewPipelineFromSlice(input).
+		Process(exporter.InputProcess()).
+		Process(model.NewMapProcess(mapper)).
+		AddSink(exporter.Sink(model.NewSinkToSlice(&result))).
+		Run()
+	assert.Nil(t, err)
+	exporter.refresh()
+
+	assert.Equal(t, 2, len(result))
+	assert.Equal(t, float64(2), exporter.linesRead.Value())
+	assert.Equal(t, float64(2), exporter.linesWritten.Value())
+	assert.Equal(t, float64(2), exporter.cacheSizes["names"].Value())
+}
+
+func TestExporterShouldReportBloomSaturation(t *testing.T) {
+	statistics.Reset()
+	cache := model.NewBoundedUniqueMemCache(2, 0, 4)
+	exporter := NewExporter()
+	exporter.WatchCaches(map[string]model.Cache{"ids": cache, "names": model.NewMemCache()})
+
+	cache.PutUnique("A", "1")
+	cache.PutUnique("B", "2")
+	cache.PutUnique("C", "3")
+	exporter.refresh()
+
+	assert.Equal(t, 0.75, exporter.saturations["ids"].Value())
+	assert.Equal(t, float64(1), exporter.evictions.Value())
+	assert.NotContains(t, exporter.saturations, "names")
+}
@@ -0,0 +1,248 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"container/list"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"time"
+
+	"github.com/cgi-fr/pimo/pkg/statistics"
+	"github.com/rs/zerolog/log"
+)
+
+type boundedEntry struct {
+	key     Entry
+	value   Entry
+	expires time.Time
+	recent  *list.Element
+	written *list.Element
+}
+
+// BoundedMemCache is a cache in memory with a maximum number of entries (least recently used entries are evicted)
+// and a time to live (entries are evicted when they are older than the ttl), evictions are counted in statistics
+type BoundedMemCache struct {
+	entries    map[Entry]*boundedEntry
+	recent     *list.List // most recently used entries first
+	written    *list.List // oldest written entries first
+	observers  map[Entry][]Observer
+	maxEntries int
+	ttl        time.Duration
+	now        func() time.Time
+}
+
+// NewBoundedMemCache creates a BoundedMemCache, a zero maxEntries or ttl means no limit
+func NewBoundedMemCache(maxEntries int, ttl time.Duration) Cache {
+	return newBoundedMemCache(maxEntries, ttl)
+}
+
+func newBoundedMemCache(maxEntries int, ttl time.Duration) *BoundedMemCache {
+	return &BoundedMemCache{
+		entries:    map[Entry]*boundedEntry{},
+		recent:     list.New(),
+		written:    list.New(),
+		observers:  map[Entry][]Observer{},
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		now:        time.Now,
+	}
+}
+
+func (bc *BoundedMemCache) Iterate() Source {
+	bc.expire()
+	collector := NewCollector()
+	for element := bc.written.Front(); element != nil; element = element.Next() {
+		entry := element.Value.(*boundedEntry)
+		collector.Collect(NewDictionary().With("key", entry.key).With("value", entry.value))
+	}
+	return collector
+}
+
+func (bc *BoundedMemCache) Len() int {
+	bc.expire()
+	return len(bc.entries)
+}
+
+func (bc *BoundedMemCache) Get(key Entry) (Entry, bool) {
+	bc.expire()
+	entry, ok := bc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	bc.recent.MoveToFront(entry.recent)
+	return entry.value, true
+}
+
+func (bc *BoundedMemCache) Put(key Entry, value Entry) {
+	observers, ok := bc.observers[key]
+	if ok {
+		for _, observer := range observers {
+			observer.Notify(key, value)
+		}
+		// observers subscribe to missing keys, they subscribe again if the key is evicted
+		delete(bc.observers, key)
+	}
+
+	bc.expire()
+	if entry, exists := bc.entries[key]; exists {
+		entry.value = value
+		entry.expires = bc.expiration()
+		bc.recent.MoveToFront(entry.recent)
+		bc.written.MoveToBack(entry.written)
+		return
+	}
+
+	entry := &boundedEntry{key: key, value: value, expires: bc.expiration()}
+	entry.recent = bc.recent.PushFront(entry)
+	entry.written = bc.written.PushBack(entry)
+	bc.entries[key] = entry
+
+	if bc.maxEntries > 0 && len(bc.entries) > bc.maxEntries {
+		bc.evict(bc.recent.Back().Value.(*boundedEntry))
+	}
+}
+
+func (bc *BoundedMemCache) Subscribe(key Entry, observer Observer) {
+	observers, ok := bc.observers[key]
+	if !ok {
+		observers = []Observer{}
+	}
+	bc.observers[key] = append(observers, observer)
+}
+
+func (bc *BoundedMemCache) expiration() time.Time {
+	if bc.ttl <= 0 {
+		return time.Time{}
+	}
+	return bc.now().Add(bc.ttl)
+}
+
+// expire evicts the entries older than the ttl, they are the first written entries
+func (bc *BoundedMemCache) expire() {
+	if bc.ttl <= 0 {
+		return
+	}
+	now := bc.now()
+	for element := bc.written.Front(); element != nil; element = bc.written.Front() {
+		entry := element.Value.(*boundedEntry)
+		if entry.expires.After(now) {
+			return
+		}
+		bc.evict(entry)
+	}
+}
+
+func (bc *BoundedMemCache) evict(entry *boundedEntry) {
+	bc.recent.Remove(entry.recent)
+	bc.written.Remove(entry.written)
+	delete(bc.entries, entry.key)
+	delete(bc.observers, entry.key)
+	statistics.IncEvictedCacheEntriesCount()
+}
+
+// Saturable is implemented by caches remembering values in a structure of limited capacity
+type Saturable interface {
+	// Saturation returns the ratio between the number of remembered values and the capacity
+	Saturation() float64
+}
+
+// BoundedUniqueMemCache is a BoundedMemCache with unique values, used values are kept in a bloom filter
+// so a value is never reused after the eviction of its key (a false positive only rejects a free value).
+// The bloom filter is never reset : beyond its capacity, more and more free values are rejected.
+type BoundedUniqueMemCache struct {
+	*BoundedMemCache
+	used     *bloomFilter
+	capacity int
+	inserts  int
+}
+
+// NewBoundedUniqueMemCache creates a BoundedUniqueMemCache, the bloom filter is sized for capacity values
+// (10 times maxEntries if capacity is zero) with a false positive rate of 1%
+func NewBoundedUniqueMemCache(maxEntries int, ttl time.Duration, capacity int) UniqueCache {
+	if capacity <= 0 {
+		capacity = 10 * maxEntries
+	}
+	return &BoundedUniqueMemCache{newBoundedMemCache(maxEntries, ttl), newBloomFilter(capacity, 0.01), capacity, 0}
+}
+
+func (buc *BoundedUniqueMemCache) Put(key Entry, value Entry) {
+	buc.BoundedMemCache.Put(key, value)
+	if buc.used.contains(value) {
+		return
+	}
+	buc.used.add(value)
+	buc.inserts++
+	if buc.inserts == buc.capacity {
+		log.Warn().Int("bloom-capacity", buc.capacity).Msg("Bloom filter of unique cache is full, free values will be rejected more and more often")
+	}
+}
+
+func (buc *BoundedUniqueMemCache) PutUnique(key Entry, value Entry) bool {
+	if buc.used.contains(value) {
+		return false
+	}
+	buc.Put(key, value)
+	return true
+}
+
+// Saturation returns the ratio between the number of values added to the bloom filter and its capacity
+func (buc *BoundedUniqueMemCache) Saturation() float64 {
+	return float64(buc.inserts) / float64(buc.capacity)
+}
+
+type bloomFilter struct {
+	bits   []uint64
+	size   uint64
+	hashes uint64
+}
+
+func newBloomFilter(capacity int, falsePositiveRate float64) *bloomFilter {
+	size := uint64(math.Ceil(-float64(capacity) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	hashes := uint64(math.Max(1, math.Round(float64(size)/float64(capacity)*math.Ln2)))
+	return &bloomFilter{make([]uint64, (size+63)/64), size, hashes}
+}
+
+// positions derives the positions of a value with double hashing
+func (bf *bloomFilter) positions(value Entry) []uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%T:%v", value, value)
+	h1 := h.Sum64()
+	h2 := h1>>33 | h1<<31 | 1
+	positions := make([]uint64, bf.hashes)
+	for i := range positions {
+		positions[i] = (h1 + uint64(i)*h2) % bf.size
+	}
+	return positions
+}
+
+func (bf *bloomFilter) add(value Entry) {
+	for _, position := range bf.positions(value) {
+		bf.bits[position/64] |= 1 << (position % 64)
+	}
+}
+
+func (bf *bloomFilter) contains(value Entry) bool {
+	for _, position := range bf.positions(value) {
+		if bf.bits[position/64]&(1<<(position%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -19,6 +19,8 @@
 
 import (
 	"fmt"
+
+	"github.com/cgi-fr/pimo/pkg/template"
 )
 
 type Cache interface {
@@ -26,6 +28,7 @@ type Cache interface {
 	Put(key Entry, value Entry)
 	Subscribe(key Entry, observer Observer)
 	Iterate() Source
+	Len() int
 }
 
 type UniqueCache interface {
@@ -37,6 +40,18 @@ type Observer interface {
 	Notify(key Entry, value Entry)
 }
 
+// NewTemplateCacheReader gives templates a read access to the caches, a missing key is read as null
+func NewTemplateCacheReader(caches map[string]Cache) template.CacheReader {
+	return func(name string, key interface{}) (interface{}, error) {
+		cache, ok := caches[name]
+		if !ok {
+			return nil, fmt.Errorf("Cache '%s' not found", name)
+		}
+		value, _ := cache.Get(key)
+		return value, nil
+	}
+}
+
 // MemCache is a cache in memory
 type MemCache struct {
 	cache     map[Entry]Entry
@@ -54,6 +69,10 @@ func (mc *MemCache) Iterate() Source {
 	return collector
 }
 
+func (mc *MemCache) Len() int {
+	return len(mc.cache)
+}
+
 func (mc *MemCache) Get(key Entry) (Entry, bool) {
 	value, ok := mc.cache[key]
 	return value, ok
@@ -154,6 +173,11 @@ func (mcce MaskContextCacheEngine) MaskContext(context Dictionary,
 	return dict, err
 }
 
+// RetryMaskEngine is implemented by masks that can produce another value when the previous attempt is already used in a unique cache
+type RetryMaskEngine interface {
+	MaskRetry(e Entry, attempt int, context ...Dictionary) (Entry, error)
+}
+
 type UniqueMaskCacheEngine struct {
 	cache          UniqueCache
 	originalEngine MaskEngine
@@ -170,8 +194,15 @@ func (umce UniqueMaskCacheEngine) Mask(e Entry, context ...Dictionary) (Entry, e
 	if isInCache {
 		return cachedValue, nil
 	}
+	retrier, canRetry := umce.originalEngine.(RetryMaskEngine)
 	for retry := 0; retry < umce.maxRetries; retry++ {
-		value, err := umce.originalEngine.Mask(e, context...)
+		var value Entry
+		var err error
+		if canRetry {
+			value, err = retrier.MaskRetry(e, retry, context...)
+		} else {
+			value, err = umce.originalEngine.Mask(e, context...)
+		}
 		if err == nil {
 			ok := umce.cache.PutUnique(e, value)
 			if ok {
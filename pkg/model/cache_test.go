@@ -22,7 +22,9 @@
 	"math/rand"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/cgi-fr/pimo/pkg/statistics"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -40,6 +42,131 @@ func TestMemCache_putUnique(t *testing.T) {
 	assert.True(t, result)
 }
 
+func TestBoundedMemCacheShouldEvictLeastRecentlyUsed(t *testing.T) {
+	statistics.Reset()
+	cache := NewBoundedMemCache(2, 0)
+
+	cache.Put("A", "1")
+	cache.Put("B", "2")
+	_, ok := cache.Get("A")
+	assert.True(t, ok)
+	cache.Put("C", "3")
+
+	_, ok = cache.Get("B")
+	assert.False(t, ok)
+	value, ok := cache.Get("A")
+	assert.True(t, ok)
+	assert.Equal(t, "1", value)
+	assert.Equal(t, 2, cache.Len())
+	assert.Equal(t, 1, statistics.Compute().GetEvictedCacheEntriesCount())
+}
+
+func TestBoundedMemCacheShouldExpireEntries(t *testing.T) {
+	statistics.Reset()
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := newBoundedMemCache(0, time.Hour)
+	cache.now = func() time.Time { return now }
+
+	cache.Put("A", "1")
+	now = now.Add(30 * time.Minute)
+	cache.Put("B", "2")
+	_, ok := cache.Get("A")
+	assert.True(t, ok)
+
+	now = now.Add(45 * time.Minute)
+	_, ok = cache.Get("A")
+	assert.False(t, ok)
+	_, ok = cache.Get("B")
+	assert.True(t, ok)
+
+	iterator := cache.Iterate()
+	assert.True(t, iterator.Next())
+	assert.Equal(t, "B", iterator.Value().Get("key"))
+	assert.False(t, iterator.Next())
+	assert.Equal(t, 1, statistics.Compute().GetEvictedCacheEntriesCount())
+}
+
+func TestBoundedUniqueMemCacheShouldNotReuseEvictedValues(t *testing.T) {
+	statistics.Reset()
+	cache := NewBoundedUniqueMemCache(1, 0, 0)
+
+	assert.True(t, cache.PutUnique("A", "1"))
+	assert.True(t, cache.PutUnique("B", "2"))
+	_, ok := cache.Get("A")
+	assert.False(t, ok)
+
+	assert.False(t, cache.PutUnique("C", "1"))
+	assert.False(t, cache.PutUnique("C", "2"))
+	assert.True(t, cache.PutUnique("C", "3"))
+}
+
+func TestBuildCachesShouldRejectInvalidTTL(t *testing.T) {
+	_, err := BuildCaches(map[string]CacheDefinition{"fakeName": {TTL: "1 day"}}, nil)
+	assert.Equal(t, errors.New("Invalid ttl '1 day' for cache fakeName"), err)
+
+	caches, err := BuildCaches(map[string]CacheDefinition{"fakeName": {Unique: true, MaxEntries: 10, TTL: "24h"}}, nil)
+	assert.Nil(t, err)
+	assert.IsType(t, &BoundedUniqueMemCache{}, caches["fakeName"])
+}
+
+func TestBuildCachesShouldCheckBloomCapacity(t *testing.T) {
+	_, err := BuildCaches(map[string]CacheDefinition{"fakeName": {Unique: true, TTL: "24h"}}, nil)
+	assert.Equal(t, errors.New("bloomCapacity is required for unique cache fakeName with a ttl and without maxEntries"), err)
+
+	_, err = BuildCaches(map[string]CacheDefinition{"fakeName": {MaxEntries: 10, BloomCapacity: 100}}, nil)
+	assert.NotNil(t, err)
+
+	caches, err := BuildCaches(map[string]CacheDefinition{"fakeName": {Unique: true, TTL: "24h", BloomCapacity: 100}}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 100, caches["fakeName"].(*BoundedUniqueMemCache).capacity)
+}
+
+func TestBoundedUniqueMemCacheShouldReportSaturation(t *testing.T) {
+	statistics.Reset()
+	cache := NewBoundedUniqueMemCache(2, 0, 4)
+
+	assert.True(t, cache.PutUnique("A", "1"))
+	assert.True(t, cache.PutUnique("B", "2"))
+	cache.Put("B", "2")
+	assert.Equal(t, 0.5, cache.(Saturable).Saturation())
+
+	assert.True(t, cache.PutUnique("C", "3"))
+	assert.True(t, cache.PutUnique("D", "4"))
+	assert.True(t, cache.PutUnique("E", "5"))
+	assert.Equal(t, 1.25, cache.(Saturable).Saturation())
+	assert.Equal(t, 2, cache.Len())
+}
+
+type countObserver struct {
+	notified int
+}
+
+func (o *countObserver) Notify(key Entry, value Entry) {
+	o.notified++
+}
+
+func TestBoundedMemCacheShouldForgetObservers(t *testing.T) {
+	statistics.Reset()
+	cache := newBoundedMemCache(1, 0)
+	observer := &countObserver{}
+
+	cache.Subscribe("A", observer)
+	cache.Subscribe("B", observer)
+	cache.Put("A", "1")
+	assert.Equal(t, 1, observer.notified)
+	assert.Len(t, cache.observers, 1)
+
+	cache.Put("B", "2")
+	cache.Put("A", "3")
+	assert.Equal(t, 2, observer.notified)
+	assert.Len(t, cache.observers, 0)
+
+	// observers of an evicted key are forgotten
+	cache.Subscribe("A", observer)
+	cache.Put("C", "4")
+	assert.Len(t, cache.observers, 0)
+}
+
 func TestNewUniqueMaskCacheEngine(t *testing.T) {
 	rand.Seed(0)
 	MyFunc := func(entry Entry, contexts ...Dictionary) (Entry, error) {
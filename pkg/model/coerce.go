@@ -0,0 +1,262 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// ToString converts a scalar entry to its string representation, dates are formatted with RFC3339
+func ToString(e Entry) (string, error) {
+	switch typed := e.(type) {
+	case string:
+		return typed, nil
+	case json.Number:
+		return typed.String(), nil
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(typed), 'f', -1, 32), nil
+	case int:
+		return strconv.Itoa(typed), nil
+	case int64:
+		return strconv.FormatInt(typed, 10), nil
+	case int32:
+		return strconv.FormatInt(int64(typed), 10), nil
+	case uint64:
+		return strconv.FormatUint(typed, 10), nil
+	case bool:
+		return strconv.FormatBool(typed), nil
+	case time.Time:
+		return typed.Format(time.RFC3339), nil
+	default:
+		return "", fmt.Errorf("cannot convert value of type %T to string", e)
+	}
+}
+
+// ToFloat64 converts a number, a numeric string or a boolean to a float
+func ToFloat64(e Entry) (float64, error) {
+	switch typed := e.(type) {
+	case json.Number:
+		return typed.Float64()
+	case float64:
+		return typed, nil
+	case float32:
+		return float64(typed), nil
+	case int:
+		return float64(typed), nil
+	case int64:
+		return float64(typed), nil
+	case int32:
+		return float64(typed), nil
+	case uint64:
+		return float64(typed), nil
+	case bool:
+		if typed {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		return strconv.ParseFloat(typed, 64)
+	case time.Time:
+		return float64(typed.Unix()), nil
+	default:
+		return 0, fmt.Errorf("cannot convert value of type %T to number", e)
+	}
+}
+
+// ToInt64 converts a number, a numeric string or a boolean to an integer, decimals are truncated
+func ToInt64(e Entry) (int64, error) {
+	switch typed := e.(type) {
+	case int:
+		return int64(typed), nil
+	case int64:
+		return typed, nil
+	case int32:
+		return int64(typed), nil
+	case json.Number:
+		if i, err := typed.Int64(); err == nil {
+			return i, nil
+		}
+	case string:
+		if i, err := strconv.ParseInt(typed, 10, 64); err == nil {
+			return i, nil
+		}
+	}
+	f, err := ToFloat64(e)
+	if err != nil {
+		return 0, fmt.Errorf("cannot convert value of type %T to integer", e)
+	}
+	return int64(math.Trunc(f)), nil
+}
+
+// ToBool converts a boolean, a string (true, false, 1, 0...) or a number (non zero is true) to a boolean
+func ToBool(e Entry) (bool, error) {
+	switch typed := e.(type) {
+	case bool:
+		return typed, nil
+	case string:
+		return strconv.ParseBool(typed)
+	}
+	f, err := ToFloat64(e)
+	if err != nil {
+		return false, fmt.Errorf("cannot convert value of type %T to boolean", e)
+	}
+	return f != 0, nil
+}
+
+// ToTime converts a date, a string in the given layout (or RFC3339) or a unix timestamp in seconds to a date
+func ToTime(e Entry, layout string) (time.Time, error) {
+	switch typed := e.(type) {
+	case time.Time:
+		return typed, nil
+	case string:
+		if layout != "" {
+			if t, err := time.Parse(layout, typed); err == nil {
+				return t, nil
+			}
+		}
+		return time.Parse(time.RFC3339, typed)
+	}
+	seconds, err := ToInt64(e)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot convert value of type %T to date", e)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+func checkCoercion(conf CoercionType) error {
+	switch conf.Name {
+	case "string", "integer", "number", "boolean", "date", "null":
+		return nil
+	default:
+		return fmt.Errorf("type '%s' is not supported, use one of string, integer, number, boolean, date, null", conf.Name)
+	}
+}
+
+// Coerce converts the entry to the type of the configuration, null values are kept
+func Coerce(e Entry, conf CoercionType) (Entry, error) {
+	if e == nil {
+		return nil, nil
+	}
+	switch conf.Name {
+	case "string":
+		if t, ok := e.(time.Time); ok && conf.Format != "" {
+			return t.Format(conf.Format), nil
+		}
+		return ToString(e)
+	case "integer":
+		return ToInt64(e)
+	case "number":
+		return ToFloat64(e)
+	case "boolean":
+		return ToBool(e)
+	case "date":
+		t, err := ToTime(e, conf.Format)
+		if err != nil {
+			return nil, err
+		}
+		if conf.Format == "" {
+			return t.Format(time.RFC3339), nil
+		}
+		return t.Format(conf.Format), nil
+	case "null":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("type '%s' is not supported", conf.Name)
+	}
+}
+
+// CoercedMaskEngine converts the result of the original mask
+type CoercedMaskEngine struct {
+	original MaskEngine
+	conf     CoercionType
+}
+
+// NewCoercedMaskEngine create a MaskEngine converting results of the original mask
+func NewCoercedMaskEngine(original MaskEngine, conf CoercionType) (CoercedMaskEngine, error) {
+	if err := checkCoercion(conf); err != nil {
+		return CoercedMaskEngine{}, err
+	}
+	return CoercedMaskEngine{original, conf}, nil
+}
+
+// Mask delegates masking to the original mask and converts the result
+func (cme CoercedMaskEngine) Mask(e Entry, context ...Dictionary) (Entry, error) {
+	masked, err := cme.original.Mask(e, context...)
+	if err != nil {
+		return nil, err
+	}
+	return Coerce(masked, cme.conf)
+}
+
+// MaskRetry delegates masking to the original mask with the attempt number if it can retry, and converts the result
+func (cme CoercedMaskEngine) MaskRetry(e Entry, attempt int, context ...Dictionary) (Entry, error) {
+	retrier, ok := cme.original.(RetryMaskEngine)
+	if !ok {
+		return cme.Mask(e, context...)
+	}
+	masked, err := retrier.MaskRetry(e, attempt, context...)
+	if err != nil {
+		return nil, err
+	}
+	return Coerce(masked, cme.conf)
+}
+
+// Seed reseeds the original mask if it uses a random generator
+func (cme CoercedMaskEngine) Seed(seed int64) {
+	if seedable, ok := cme.original.(Seedable); ok {
+		seedable.Seed(seed)
+	}
+}
+
+// CoercedMaskContextEngine converts the value written by the original mask
+type CoercedMaskContextEngine struct {
+	original MaskContextEngine
+	conf     CoercionType
+}
+
+// NewCoercedMaskContextEngine create a MaskContextEngine converting values written by the original mask
+func NewCoercedMaskContextEngine(original MaskContextEngine, conf CoercionType) (CoercedMaskContextEngine, error) {
+	if err := checkCoercion(conf); err != nil {
+		return CoercedMaskContextEngine{}, err
+	}
+	return CoercedMaskContextEngine{original, conf}, nil
+}
+
+// MaskContext delegates masking to the original mask and converts the value of the key if it is still present
+func (cmce CoercedMaskContextEngine) MaskContext(e Dictionary, key string, context ...Dictionary) (Dictionary, error) {
+	result, err := cmce.original.MaskContext(e, key, context...)
+	if err != nil {
+		return result, err
+	}
+	value, ok := result.GetValue(key)
+	if !ok {
+		return result, nil
+	}
+	coerced, err := Coerce(value, cmce.conf)
+	if err != nil {
+		return result, err
+	}
+	result.Set(key, coerced)
+	return result, nil
+}
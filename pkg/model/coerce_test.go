@@ -0,0 +1,118 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoerceShouldConvertValues(t *testing.T) {
+	date := time.Date(2021, 3, 14, 15, 9, 26, 0, time.UTC)
+	tests := []struct {
+		value  Entry
+		conf   CoercionType
+		wanted Entry
+	}{
+		{json.Number("42"), CoercionType{Name: "string"}, "42"},
+		{12.5, CoercionType{Name: "string"}, "12.5"},
+		{true, CoercionType{Name: "string"}, "true"},
+		{date, CoercionType{Name: "string"}, "2021-03-14T15:09:26Z"},
+		{date, CoercionType{Name: "string", Format: "02/01/2006"}, "14/03/2021"},
+		{"42", CoercionType{Name: "integer"}, int64(42)},
+		{json.Number("42.9"), CoercionType{Name: "integer"}, int64(42)},
+		{7, CoercionType{Name: "integer"}, int64(7)},
+		{"12.5", CoercionType{Name: "number"}, 12.5},
+		{json.Number("3"), CoercionType{Name: "number"}, 3.0},
+		{"true", CoercionType{Name: "boolean"}, true},
+		{json.Number("0"), CoercionType{Name: "boolean"}, false},
+		{date, CoercionType{Name: "date"}, "2021-03-14T15:09:26Z"},
+		{"14/03/2021", CoercionType{Name: "date", Format: "02/01/2006"}, "14/03/2021"},
+		{"2021-03-14T15:09:26Z", CoercionType{Name: "date", Format: "2006-01-02"}, "2021-03-14"},
+		{json.Number("1615734566"), CoercionType{Name: "date"}, "2021-03-14T15:09:26Z"},
+		{"anything", CoercionType{Name: "null"}, nil},
+		{nil, CoercionType{Name: "integer"}, nil},
+	}
+	for _, tt := range tests {
+		result, err := Coerce(tt.value, tt.conf)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.wanted, result, "%v to %v", tt.value, tt.conf)
+	}
+}
+
+func TestCoerceShouldFailOnInvalidValues(t *testing.T) {
+	_, err := Coerce("abc", CoercionType{Name: "integer"})
+	assert.NotNil(t, err)
+	_, err = Coerce(NewDictionary(), CoercionType{Name: "string"})
+	assert.NotNil(t, err)
+	_, err = Coerce("maybe", CoercionType{Name: "boolean"})
+	assert.NotNil(t, err)
+}
+
+func TestCoercedMaskEngineShouldRejectUnknownType(t *testing.T) {
+	_, err := NewCoercedMaskEngine(FunctionMaskEngine{Function: func(e Entry, c ...Dictionary) (Entry, error) { return e, nil }}, CoercionType{Name: "uuid"})
+	assert.NotNil(t, err)
+}
+
+func TestPipelineWithTypeShouldConvertMaskResults(t *testing.T) {
+	InjectMaskFactories([]MaskFactory{func(conf Masking, seed int64, caches map[string]Cache) (MaskEngine, bool, error) {
+		if conf.Mask.Constant != nil {
+			return FunctionMaskEngine{Function: func(name Entry, contexts ...Dictionary) (Entry, error) { return conf.Mask.Constant, nil }}, true, nil
+		}
+		return nil, false, nil
+	}})
+	defer InjectMaskFactories(nil)
+
+	definition := Definition{Masking: []Masking{
+		{Selector: SelectorType{Jsonpath: "age"}, Mask: MaskType{Constant: "42"}, Type: &CoercionType{Name: "integer"}},
+	}}
+	pipeline, _, err := BuildPipeline(NewPipelineFromSlice([]Dictionary{NewDictionary().With("age", 12)}), definition, nil)
+	assert.Nil(t, err)
+
+	var result []Dictionary
+	err = pipeline.AddSink(NewSinkToSlice(&result)).Run()
+	assert.Nil(t, err)
+	assert.Equal(t, []Dictionary{NewDictionary().With("age", int64(42))}, result)
+}
+
+type attemptMask struct{}
+
+func (m attemptMask) Mask(e Entry, context ...Dictionary) (Entry, error) {
+	return "0", nil
+}
+
+func (m attemptMask) MaskRetry(e Entry, attempt int, context ...Dictionary) (Entry, error) {
+	return fmt.Sprint(attempt), nil
+}
+
+func TestCoercedMaskEngineShouldRetryWithUniqueCache(t *testing.T) {
+	coerced, err := NewCoercedMaskEngine(attemptMask{}, CoercionType{Name: "integer"})
+	assert.Nil(t, err)
+	mask := NewUniqueMaskCacheEngine(NewUniqueMemCache(), coerced)
+
+	first, err := mask.Mask("a")
+	assert.Nil(t, err)
+	second, err := mask.Mask("b")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), first)
+	assert.Equal(t, int64(1), second)
+}
@@ -19,6 +19,7 @@
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -42,6 +43,17 @@ type HasCleaner interface {
 	GetCleaner() FunctionMaskContextEngine
 }
 
+// Stateful interface is implemented by masks keeping a state between lines, the state can be saved to resume a run
+type Stateful interface {
+	State() (json.RawMessage, error)
+	Restore(json.RawMessage) error
+}
+
+// Seedable interface is implemented by masks using a random generator that can be reseeded
+type Seedable interface {
+	Seed(int64)
+}
+
 // FunctionMaskEngine implements MaskEngine with a simple function
 type FunctionMaskEngine struct {
 	Function func(Entry, ...Dictionary) (Entry, error)
@@ -118,6 +130,12 @@ type PipeType struct {
 	InjectParent   string    `yaml:"injectParent,omitempty"`
 	InjectRoot     string    `yaml:"injectRoot,omitempty"`
 	DefinitionFile string    `yaml:"file,omitempty"`
+	Repeat         int       `yaml:"repeat,omitempty"`
+	Filter         string    `yaml:"filter,omitempty"`
+	SortBy         string    `yaml:"sortBy,omitempty"`
+	Order          string    `yaml:"order,omitempty" jsonschema:"enum=asc,enum=desc"`
+	Limit          int       `yaml:"limit,omitempty"`
+	Sample         int       `yaml:"sample,omitempty"`
 }
 
 type TemplateEachType struct {
@@ -130,35 +148,176 @@ type LuhnType struct {
 	Universe string `yaml:"universe,omitempty"`
 }
 
+type EmailType struct {
+	Local      string   `yaml:"local,omitempty"`
+	Domains    []string `yaml:"domains,omitempty"`
+	KeepDomain bool     `yaml:"keepDomain,omitempty"`
+}
+
+type PhoneType struct {
+	Country     string `yaml:"country,omitempty"`
+	Type        string `yaml:"type,omitempty"`
+	Format      string `yaml:"format,omitempty"`
+	KeepCountry bool   `yaml:"keepCountry,omitempty"`
+	KeepType    bool   `yaml:"keepType,omitempty"`
+}
+
+type IBANType struct {
+	Country     string       `yaml:"country,omitempty"`
+	KeepCountry bool         `yaml:"keepCountry,omitempty"`
+	KeepBank    bool         `yaml:"keepBank,omitempty"`
+	FF1         *IBANFF1Type `yaml:"ff1,omitempty"`
+}
+
+type IBANFF1Type struct {
+	KeyFromEnv string `yaml:"keyFromEnv"`
+	TweakField string `yaml:"tweakField,omitempty"`
+	Decrypt    bool   `yaml:"decrypt,omitempty"`
+}
+
+type BICType struct {
+	Country   string `yaml:"country,omitempty"`
+	IBANField string `yaml:"ibanField,omitempty"`
+}
+
+type NIRType struct {
+	GenderField     string `yaml:"genderField,omitempty"`
+	BirthDateField  string `yaml:"birthDateField,omitempty"`
+	BirthPlaceField string `yaml:"birthPlaceField,omitempty"`
+}
+
+type SIRETType struct {
+	SIRENField string `yaml:"sirenField,omitempty"`
+}
+
+type INSEEType struct {
+	KeepDepartment bool `yaml:"keepDepartment,omitempty"`
+}
+
+type CreditCardType struct {
+	Networks      []string `yaml:"networks,omitempty"`
+	KeepBIN       int      `yaml:"keepBIN,omitempty"`
+	KeepLast4     bool     `yaml:"keepLast4,omitempty"`
+	Spaces        bool     `yaml:"spaces,omitempty"`
+	Generate      string   `yaml:"generate,omitempty"`
+	PANField      string   `yaml:"panField,omitempty"`
+	ExpiryFormat  string   `yaml:"expiryFormat,omitempty"`
+	ReferenceDate string   `yaml:"referenceDate,omitempty"`
+}
+
+type IPType struct {
+	KeyFromEnv string `yaml:"keyFromEnv"`
+}
+
+type MACType struct {
+	KeepOUI bool `yaml:"keepOUI,omitempty"`
+}
+
+type URLType struct {
+	Host       bool     `yaml:"host,omitempty"`
+	Path       bool     `yaml:"path,omitempty"`
+	Query      []string `yaml:"query,omitempty"`
+	KeyFromEnv string   `yaml:"keyFromEnv,omitempty"`
+}
+
+type TextRedactType struct {
+	Detectors []DetectorType `yaml:"detectors"`
+}
+
+type DetectorType struct {
+	Type  string    `yaml:"type,omitempty"`
+	Regex string    `yaml:"regex,omitempty"`
+	Mask  *MaskType `yaml:"mask,omitempty"`
+	Cache string    `yaml:"cache,omitempty"`
+}
+
+type EmbeddedType struct {
+	Codec   string    `yaml:"codec"`
+	Masking []Masking `yaml:"masking"`
+}
+
+type GeneralizeType struct {
+	Width         float64   `yaml:"width,omitempty"`
+	Breakpoints   []float64 `yaml:"breakpoints,omitempty"`
+	Date          string    `yaml:"date,omitempty" jsonschema:"enum=week,enum=month,enum=quarter,enum=year"`
+	Age           bool      `yaml:"age,omitempty"`
+	ReferenceDate string    `yaml:"referenceDate,omitempty"`
+	Format        string    `yaml:"format,omitempty"`
+	Output        string    `yaml:"output,omitempty" jsonschema:"enum=range,enum=midpoint,enum=lower"`
+	Template      string    `yaml:"template,omitempty"`
+}
+
+type AddressType struct {
+	Locale string            `yaml:"locale,omitempty" jsonschema:"enum=FR,enum=DE,enum=ES,enum=IT,enum=BE,enum=UK"`
+	Fields AddressFieldsType `yaml:"fields,omitempty"`
+	Keep   string            `yaml:"keep,omitempty" jsonschema:"enum=city,enum=region"`
+}
+
+type AddressFieldsType struct {
+	Number   string `yaml:"number,omitempty"`
+	Street   string `yaml:"street,omitempty"`
+	Postcode string `yaml:"postcode,omitempty"`
+	City     string `yaml:"city,omitempty"`
+	Country  string `yaml:"country,omitempty"`
+}
+
+type SeederType struct {
+	Field    string `yaml:"field,omitempty"`
+	Template string `yaml:"template,omitempty"`
+}
+
+// CoercionType converts the result of masks, the format is a Go time layout for dates
+type CoercionType struct {
+	Name   string `yaml:"name" jsonschema:"enum=string,enum=integer,enum=number,enum=boolean,enum=date,enum=null"`
+	Format string `yaml:"format,omitempty"`
+}
+
 type MaskType struct {
-	Add               Entry                `yaml:"add,omitempty" jsonschema:"oneof_required=Add"`
-	AddTransient      Entry                `yaml:"add-transient,omitempty" jsonschema:"oneof_required=AddTransient"`
-	Constant          Entry                `yaml:"constant,omitempty" jsonschema:"oneof_required=Constant"`
-	RandomChoice      []Entry              `yaml:"randomChoice,omitempty" jsonschema:"oneof_required=RandomChoice"`
-	RandomChoiceInURI string               `yaml:"randomChoiceInUri,omitempty" jsonschema:"oneof_required=RandomChoiceInURI"`
-	Command           string               `yaml:"command,omitempty" jsonschema:"oneof_required=Command"`
-	RandomInt         RandIntType          `yaml:"randomInt,omitempty" jsonschema:"oneof_required=RandomInt"`
-	WeightedChoice    []WeightedChoiceType `yaml:"weightedChoice,omitempty" jsonschema:"oneof_required=WeightedChoice"`
-	Regex             string               `yaml:"regex,omitempty" jsonschema:"oneof_required=Regex"`
-	Hash              []Entry              `yaml:"hash,omitempty" jsonschema:"oneof_required=Hash"`
-	HashInURI         string               `yaml:"hashInUri,omitempty" jsonschema:"oneof_required=HashInURI"`
-	RandDate          RandDateType         `yaml:"randDate,omitempty" jsonschema:"oneof_required=RandDate"`
-	Incremental       IncrementalType      `yaml:"incremental,omitempty" jsonschema:"oneof_required=Incremental"`
-	Replacement       string               `yaml:"replacement,omitempty" jsonschema:"oneof_required=Replacement"`
-	Template          string               `yaml:"template,omitempty" jsonschema:"oneof_required=Template"`
-	TemplateEach      TemplateEachType     `yaml:"template-each,omitempty" jsonschema:"oneof_required=TemplateEach"`
-	Duration          string               `yaml:"duration,omitempty" jsonschema:"oneof_required=Duration"`
-	Remove            bool                 `yaml:"remove,omitempty" jsonschema:"oneof_required=Remove"`
-	RangeMask         int                  `yaml:"range,omitempty" jsonschema:"oneof_required=RangeMask"`
-	RandomDuration    RandomDurationType   `yaml:"randomDuration,omitempty" jsonschema:"oneof_required=RandomDuration"`
-	FluxURI           string               `yaml:"fluxUri,omitempty" jsonschema:"oneof_required=FluxURI"`
-	RandomDecimal     RandomDecimalType    `yaml:"randomDecimal,omitempty" jsonschema:"oneof_required=RandomDecimal"`
-	DateParser        DateParserType       `yaml:"dateParser,omitempty" jsonschema:"oneof_required=DateParser"`
-	FromCache         string               `yaml:"fromCache,omitempty" jsonschema:"oneof_required=FromCache"`
-	FF1               FF1Type              `yaml:"ff1,omitempty" jsonschema:"oneof_required=FF1"`
-	Pipe              PipeType             `yaml:"pipe,omitempty" jsonschema:"oneof_required=Pipe"`
-	FromJSON          string               `yaml:"fromjson,omitempty" jsonschema:"oneof_required=FromJSON"`
-	Luhn              *LuhnType            `yaml:"luhn,omitempty" jsonschema:"oneof_required=Luhn"`
+	Add                 Entry                `yaml:"add,omitempty" jsonschema:"oneof_required=Add"`
+	AddTransient        Entry                `yaml:"add-transient,omitempty" jsonschema:"oneof_required=AddTransient"`
+	Constant            Entry                `yaml:"constant,omitempty" jsonschema:"oneof_required=Constant"`
+	RandomChoice        []Entry              `yaml:"randomChoice,omitempty" jsonschema:"oneof_required=RandomChoice"`
+	RandomChoiceInURI   string               `yaml:"randomChoiceInUri,omitempty" jsonschema:"oneof_required=RandomChoiceInURI"`
+	Command             string               `yaml:"command,omitempty" jsonschema:"oneof_required=Command"`
+	RandomInt           RandIntType          `yaml:"randomInt,omitempty" jsonschema:"oneof_required=RandomInt"`
+	WeightedChoice      []WeightedChoiceType `yaml:"weightedChoice,omitempty" jsonschema:"oneof_required=WeightedChoice"`
+	WeightedChoiceInURI string               `yaml:"weightedChoiceInUri,omitempty" jsonschema:"oneof_required=WeightedChoiceInURI"`
+	Regex               string               `yaml:"regex,omitempty" jsonschema:"oneof_required=Regex"`
+	Hash                []Entry              `yaml:"hash,omitempty" jsonschema:"oneof_required=Hash"`
+	HashInURI           string               `yaml:"hashInUri,omitempty" jsonschema:"oneof_required=HashInURI"`
+	RandDate            RandDateType         `yaml:"randDate,omitempty" jsonschema:"oneof_required=RandDate"`
+	Incremental         IncrementalType      `yaml:"incremental,omitempty" jsonschema:"oneof_required=Incremental"`
+	Replacement         string               `yaml:"replacement,omitempty" jsonschema:"oneof_required=Replacement"`
+	Template            string               `yaml:"template,omitempty" jsonschema:"oneof_required=Template"`
+	TemplateEach        TemplateEachType     `yaml:"template-each,omitempty" jsonschema:"oneof_required=TemplateEach"`
+	Duration            string               `yaml:"duration,omitempty" jsonschema:"oneof_required=Duration"`
+	Remove              bool                 `yaml:"remove,omitempty" jsonschema:"oneof_required=Remove"`
+	RangeMask           int                  `yaml:"range,omitempty" jsonschema:"oneof_required=RangeMask"`
+	RandomDuration      RandomDurationType   `yaml:"randomDuration,omitempty" jsonschema:"oneof_required=RandomDuration"`
+	FluxURI             string               `yaml:"fluxUri,omitempty" jsonschema:"oneof_required=FluxURI"`
+	RandomDecimal       RandomDecimalType    `yaml:"randomDecimal,omitempty" jsonschema:"oneof_required=RandomDecimal"`
+	DateParser          DateParserType       `yaml:"dateParser,omitempty" jsonschema:"oneof_required=DateParser"`
+	FromCache           string               `yaml:"fromCache,omitempty" jsonschema:"oneof_required=FromCache"`
+	FF1                 FF1Type              `yaml:"ff1,omitempty" jsonschema:"oneof_required=FF1"`
+	Pipe                PipeType             `yaml:"pipe,omitempty" jsonschema:"oneof_required=Pipe"`
+	FromJSON            string               `yaml:"fromjson,omitempty" jsonschema:"oneof_required=FromJSON"`
+	Luhn                *LuhnType            `yaml:"luhn,omitempty" jsonschema:"oneof_required=Luhn"`
+	Email               *EmailType           `yaml:"email,omitempty" jsonschema:"oneof_required=Email"`
+	Phone               *PhoneType           `yaml:"phone,omitempty" jsonschema:"oneof_required=Phone"`
+	IBAN                *IBANType            `yaml:"iban,omitempty" jsonschema:"oneof_required=IBAN"`
+	BIC                 *BICType             `yaml:"bic,omitempty" jsonschema:"oneof_required=BIC"`
+	NIR                 *NIRType             `yaml:"nir,omitempty" jsonschema:"oneof_required=NIR"`
+	SIREN               bool                 `yaml:"siren,omitempty" jsonschema:"oneof_required=SIREN"`
+	SIRET               *SIRETType           `yaml:"siret,omitempty" jsonschema:"oneof_required=SIRET"`
+	INSEE               *INSEEType           `yaml:"insee,omitempty" jsonschema:"oneof_required=INSEE"`
+	CreditCard          *CreditCardType      `yaml:"creditCard,omitempty" jsonschema:"oneof_required=CreditCard"`
+	IP                  *IPType              `yaml:"ip,omitempty" jsonschema:"oneof_required=IP"`
+	MAC                 *MACType             `yaml:"mac,omitempty" jsonschema:"oneof_required=MAC"`
+	URL                 *URLType             `yaml:"url,omitempty" jsonschema:"oneof_required=URL"`
+	TextRedact          *TextRedactType      `yaml:"textRedact,omitempty" jsonschema:"oneof_required=TextRedact"`
+	Embedded            *EmbeddedType        `yaml:"embedded,omitempty" jsonschema:"oneof_required=Embedded"`
+	Generalize          *GeneralizeType      `yaml:"generalize,omitempty" jsonschema:"oneof_required=Generalize"`
+	Address             *AddressType         `yaml:"address,omitempty" jsonschema:"oneof_required=Address"`
 }
 
 type Masking struct {
@@ -173,17 +332,51 @@ type Masking struct {
 	Masks     []MaskType     `yaml:"masks,omitempty" jsonschema:"oneof_required=case2,oneof_required=case4"`
 	Cache     string         `yaml:"cache,omitempty"`
 	Preserve  string         `yaml:"preserve,omitempty"`
+	Seeder    *SeederType    `yaml:"seeder,omitempty"`
+	Type      *CoercionType  `yaml:"type,omitempty"`
+	Target    string         `yaml:"target,omitempty" jsonschema:"enum=value,enum=key"`
 }
 
 type CacheDefinition struct {
-	Unique bool `yaml:"unique,omitempty"`
+	Unique        bool   `yaml:"unique,omitempty"`
+	MaxEntries    int    `yaml:"maxEntries,omitempty"`
+	TTL           string `yaml:"ttl,omitempty"`
+	BloomCapacity int    `yaml:"bloomCapacity,omitempty"`
+}
+
+type EntityDefinition struct {
+	Name        string           `yaml:"name"`
+	Parent      string           `yaml:"parent,omitempty"`
+	Cardinality *CardinalityType `yaml:"cardinality,omitempty"`
+	References  []ReferenceType  `yaml:"references,omitempty"`
+	Field       string           `yaml:"field,omitempty"`
+	File        string           `yaml:"file,omitempty"`
+	Masking     []Masking        `yaml:"masking,omitempty"`
+}
+
+type CardinalityType struct {
+	Min          int                     `yaml:"min,omitempty"`
+	Max          int                     `yaml:"max,omitempty"`
+	Distribution []CardinalityWeightType `yaml:"distribution,omitempty"`
+}
+
+type CardinalityWeightType struct {
+	Count  int  `yaml:"count"`
+	Weight uint `yaml:"weight"`
+}
+
+type ReferenceType struct {
+	Field  string `yaml:"field"`
+	Parent string `yaml:"parent"`
 }
 
 type Definition struct {
-	Version string                     `yaml:"version"`
-	Seed    int64                      `yaml:"seed,omitempty"`
-	Masking []Masking                  `yaml:"masking"`
-	Caches  map[string]CacheDefinition `yaml:"caches,omitempty"`
+	Version  string                     `yaml:"version"`
+	Seed     int64                      `yaml:"seed,omitempty"`
+	Masking  []Masking                  `yaml:"masking"`
+	Caches   map[string]CacheDefinition `yaml:"caches,omitempty"`
+	Datasets []string                   `yaml:"datasets,omitempty"`
+	Entities []EntityDefinition         `yaml:"entities,omitempty"`
 }
 
 /***************
@@ -263,8 +456,13 @@ func (source *SourceFromSlice) Open() error {
 	return nil
 }
 
-func NewRepeaterUntilProcess(source *TempSource, text, mode string) (Processor, error) {
-	eng, err := template.NewEngine(text)
+// NewTemplateEngine creates a template engine drawing random values from the source, with a read access to the caches
+func NewTemplateEngine(text string, source *RandSource, caches map[string]Cache) (*template.Engine, error) {
+	return template.NewEngine(text, template.WithRand(source), template.WithCacheReader(NewTemplateCacheReader(caches)))
+}
+
+func NewRepeaterUntilProcess(source *TempSource, text, mode string, seed int64, caches map[string]Cache) (Processor, error) {
+	eng, err := NewTemplateEngine(text, NewRandSource(seed), caches)
 
 	return RepeaterUntilProcess{eng, source, mode}, err
 }
@@ -425,6 +425,77 @@ func TestMaskEngineShouldMaskNestedNestedArrays(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
+func TestMaskEngineShouldMaskArraysOfScalarArrays(t *testing.T) {
+	i := 0
+	nameMasking := FunctionMaskEngine{Function: func(name Entry, contexts ...Dictionary) (Entry, error) { i++; return fmt.Sprintf("%d", i), nil }}
+
+	iput := `{"tags":[["a","b"],[],["c",null,""]]}`
+	oput := `{"tags":[["1","2"],[],["3",null,"4"]]}`
+
+	mySlice := jsonlineToDictionaries(iput)
+
+	var result []Dictionary
+
+	pipeline := NewPipelineFromSlice(mySlice).
+		Process(NewMaskEngineProcess(NewPathSelector("tags"), nameMasking, "null")).
+		AddSink(NewSinkToSlice(&result))
+	err := pipeline.Run()
+
+	assert.Nil(t, err)
+
+	expected := DictionariesToJSONLine(jsonlineToDictionaries(oput))
+	actual := DictionariesToJSONLine(result)
+	assert.Equal(t, expected, actual)
+}
+
+func TestMaskKeyEngineShouldRenameKeys(t *testing.T) {
+	keyMasking := FunctionMaskEngine{Function: func(key Entry, contexts ...Dictionary) (Entry, error) { return strings.ToUpper(key.(string)), nil }}
+
+	iput := `{"id":1,"contacts":{"b@mail.com":{"age":20},"a@mail.com":"x","c@mail.com":null},"persons":[{"k":1,"l":2},null]}`
+	oput := `{"id":1,"contacts":{"B@MAIL.COM":{"age":20},"A@MAIL.COM":"x","C@MAIL.COM":null},"persons":[{"K":1,"L":2},null]}`
+
+	mySlice := jsonlineToDictionaries(iput)
+
+	var result []Dictionary
+
+	pipeline := NewPipelineFromSlice(mySlice).
+		Process(NewMaskKeyEngineProcess(NewPathSelector("contacts"), keyMasking, "")).
+		Process(NewMaskKeyEngineProcess(NewPathSelector("persons"), keyMasking, "")).
+		AddSink(NewSinkToSlice(&result))
+	err := pipeline.Run()
+
+	assert.Nil(t, err)
+
+	expected := DictionariesToJSONLine(jsonlineToDictionaries(oput))
+	actual := DictionariesToJSONLine(result)
+	assert.Equal(t, expected, actual)
+}
+
+func TestMaskKeyEngineShouldReturnError(t *testing.T) {
+	keyMasking := FunctionMaskEngine{Function: func(key Entry, contexts ...Dictionary) (Entry, error) { return "same", nil }}
+
+	for _, iput := range []string{`{"contacts":{"a":1,"b":2}}`, `{"contacts":"a"}`} {
+		var result []Dictionary
+
+		pipeline := NewPipelineFromSlice(jsonlineToDictionaries(iput)).
+			Process(NewMaskKeyEngineProcess(NewPathSelector("contacts"), keyMasking, "")).
+			AddSink(NewSinkToSlice(&result))
+		err := pipeline.Run()
+
+		assert.NotNil(t, err)
+	}
+}
+
+func TestBuildPipelineShouldRejectUnknownTarget(t *testing.T) {
+	definition := Definition{
+		Masking: []Masking{
+			{Selector: SelectorType{Jsonpath: "contacts"}, Target: "unknown", Mask: MaskType{Constant: "x"}},
+		},
+	}
+	_, _, err := BuildPipeline(NewPipelineFromSlice([]Dictionary{}), definition, nil)
+	assert.NotNil(t, err)
+}
+
 func TestInOutFormat1(t *testing.T) {
 	masking := FunctionMaskEngine{Function: func(name Entry, contexts ...Dictionary) (Entry, error) { return "mask", nil }}
 	var odict []Dictionary
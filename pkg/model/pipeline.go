@@ -20,6 +20,7 @@
 import (
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"io/ioutil"
 	"strings"
 	"time"
@@ -33,6 +34,7 @@
 	maskFactories        []MaskFactory
 	skipLineOnError      bool
 	skipFieldOnError     bool
+	statefulMasks        = map[string]Stateful{}
 )
 
 func InjectMaskContextFactories(factories []MaskContextFactory) {
@@ -48,24 +50,62 @@ func InjectConfig(skipLineOnErrorValue bool, skipFieldOnErrorValue bool) {
 	skipFieldOnError = skipFieldOnErrorValue
 }
 
-func BuildCaches(caches map[string]CacheDefinition, existing map[string]Cache) map[string]Cache {
+// GetStatefulMasks returns masks built by BuildPipeline that keep a state between lines,
+// keys are made of the creation order and the jsonpath so they are stable for a given configuration
+func GetStatefulMasks() map[string]Stateful {
+	return statefulMasks
+}
+
+func registerStateful(jsonpath string, mask interface{}) {
+	if stateful, ok := mask.(Stateful); ok {
+		statefulMasks[fmt.Sprintf("%d:%s", len(statefulMasks), jsonpath)] = stateful
+	}
+}
+
+func BuildCaches(caches map[string]CacheDefinition, existing map[string]Cache) (map[string]Cache, error) {
 	if existing == nil {
 		existing = map[string]Cache{}
 	}
 	for name, conf := range caches {
-		if _, exist := existing[name]; !exist {
-			if conf.Unique {
-				existing[name] = NewUniqueMemCache()
-			} else {
-				existing[name] = NewMemCache()
+		if _, exist := existing[name]; exist {
+			continue
+		}
+		var ttl time.Duration
+		if conf.TTL != "" {
+			var err error
+			if ttl, err = time.ParseDuration(conf.TTL); err != nil || ttl <= 0 {
+				return nil, errors.New("Invalid ttl '" + conf.TTL + "' for cache " + name)
 			}
 		}
+		if conf.MaxEntries < 0 {
+			return nil, errors.New("Invalid maxEntries for cache " + name)
+		}
+		bounded := conf.MaxEntries > 0 || ttl > 0
+		if conf.BloomCapacity < 0 || (conf.BloomCapacity > 0 && !(bounded && conf.Unique)) {
+			return nil, errors.New("Invalid bloomCapacity for cache " + name + ", it must be positive and only set on a unique cache with maxEntries or ttl")
+		}
+		switch {
+		case !bounded && conf.Unique:
+			existing[name] = NewUniqueMemCache()
+		case !bounded:
+			existing[name] = NewMemCache()
+		case conf.Unique:
+			if conf.MaxEntries == 0 && conf.BloomCapacity == 0 {
+				return nil, errors.New("bloomCapacity is required for unique cache " + name + " with a ttl and without maxEntries")
+			}
+			existing[name] = NewBoundedUniqueMemCache(conf.MaxEntries, ttl, conf.BloomCapacity)
+		default:
+			existing[name] = NewBoundedMemCache(conf.MaxEntries, ttl)
+		}
 	}
-	return existing
+	return existing, nil
 }
 
 func BuildPipeline(pipeline Pipeline, conf Definition, caches map[string]Cache) (Pipeline, map[string]Cache, error) {
-	caches = BuildCaches(conf.Caches, caches)
+	caches, err := BuildCaches(conf.Caches, caches)
+	if err != nil {
+		return nil, nil, err
+	}
 	cleaners := []Processor{}
 
 	for _, masking := range conf.Masking {
@@ -74,6 +114,10 @@ func BuildPipeline(pipeline Pipeline, conf Definition, caches map[string]Cache)
 			allSelectors = append(allSelectors, sel)
 		}
 
+		if masking.Target != "" && masking.Target != "value" && masking.Target != "key" {
+			return nil, nil, errors.New("Unknown target '" + masking.Target + "' for " + masking.Selector.Jsonpath)
+		}
+
 		for _, sel := range allSelectors {
 			nbArg := 0
 
@@ -87,9 +131,15 @@ func BuildPipeline(pipeline Pipeline, conf Definition, caches map[string]Cache)
 					Masks:     nil,
 					Cache:     masking.Cache,
 					Preserve:  masking.Preserve,
+					Seeder:    masking.Seeder,
+					Type:      masking.Type,
+					Target:    masking.Target,
 				}
 
 				if virtualMask.Mask.FromCache != "" {
+					if virtualMask.Target == "key" {
+						return nil, nil, errors.New("fromCache can't be applied on keys for " + virtualMask.Selector.Jsonpath)
+					}
 					cache, ok := caches[virtualMask.Mask.FromCache]
 					if !ok {
 						return nil, nil, errors.New("Cache '" + virtualMask.Cache + "' not found for '" + virtualMask.Selector.Jsonpath + "'")
@@ -104,19 +154,31 @@ func BuildPipeline(pipeline Pipeline, conf Definition, caches map[string]Cache)
 						return nil, nil, errors.New(err.Error() + " for " + virtualMask.Selector.Jsonpath)
 					}
 					if present {
-						if virtualMask.Cache != "" {
-							cache, ok := caches[virtualMask.Cache]
-							if !ok {
-								return nil, nil, errors.New("Cache '" + virtualMask.Cache + "' not found for '" + virtualMask.Selector.Jsonpath + "'")
+						registerStateful(virtualMask.Selector.Jsonpath, mask)
+						if virtualMask.Seeder != nil {
+							// set differents seeds for differents jsonpath
+							h := fnv.New64a()
+							h.Write([]byte(virtualMask.Selector.Jsonpath))
+							mask, err = NewSeededMaskEngine(mask, conf.Seed+int64(h.Sum64()), *virtualMask.Seeder)
+							if err != nil {
+								return nil, nil, errors.New(err.Error() + " for " + virtualMask.Selector.Jsonpath)
 							}
-							switch typedCache := cache.(type) {
-							case UniqueCache:
-								mask = NewUniqueMaskCacheEngine(typedCache, mask)
-							default:
-								mask = NewMaskCacheEngine(typedCache, mask)
+						}
+						if virtualMask.Type != nil {
+							mask, err = NewCoercedMaskEngine(mask, *virtualMask.Type)
+							if err != nil {
+								return nil, nil, errors.New(err.Error() + " for " + virtualMask.Selector.Jsonpath)
 							}
 						}
-						pipeline = pipeline.Process(NewMaskEngineProcess(NewPathSelector(virtualMask.Selector.Jsonpath), mask, virtualMask.Preserve))
+						mask, err = cacheMaskEngine(mask, virtualMask, caches)
+						if err != nil {
+							return nil, nil, err
+						}
+						if virtualMask.Target == "key" {
+							pipeline = pipeline.Process(NewMaskKeyEngineProcess(NewPathSelector(virtualMask.Selector.Jsonpath), mask, virtualMask.Preserve))
+						} else {
+							pipeline = pipeline.Process(NewMaskEngineProcess(NewPathSelector(virtualMask.Selector.Jsonpath), mask, virtualMask.Preserve))
+						}
 						nbArg++
 					}
 				}
@@ -127,6 +189,26 @@ func BuildPipeline(pipeline Pipeline, conf Definition, caches map[string]Cache)
 						return nil, nil, errors.New(err.Error() + " for " + virtualMask.Selector.Jsonpath)
 					}
 					if present {
+						if virtualMask.Target == "key" {
+							return nil, nil, errors.New("this mask can't be applied on keys for " + virtualMask.Selector.Jsonpath)
+						}
+						registerStateful(virtualMask.Selector.Jsonpath, mask)
+						i, hasCleaner := mask.(HasCleaner)
+						if virtualMask.Seeder != nil {
+							// set differents seeds for differents jsonpath
+							h := fnv.New64a()
+							h.Write([]byte(virtualMask.Selector.Jsonpath))
+							mask, err = NewSeededMaskContextEngine(mask, conf.Seed+int64(h.Sum64()), *virtualMask.Seeder)
+							if err != nil {
+								return nil, nil, errors.New(err.Error() + " for " + virtualMask.Selector.Jsonpath)
+							}
+						}
+						if virtualMask.Type != nil {
+							mask, err = NewCoercedMaskContextEngine(mask, *virtualMask.Type)
+							if err != nil {
+								return nil, nil, errors.New(err.Error() + " for " + virtualMask.Selector.Jsonpath)
+							}
+						}
 						if virtualMask.Cache != "" {
 							cache, ok := caches[virtualMask.Cache]
 							if !ok {
@@ -134,6 +216,9 @@ func BuildPipeline(pipeline Pipeline, conf Definition, caches map[string]Cache)
 							}
 							switch typedCache := cache.(type) {
 							case UniqueCache:
+								if virtualMask.Seeder != nil {
+									return nil, nil, errors.New("seeder cannot be used with unique cache '" + virtualMask.Cache + "' for " + virtualMask.Selector.Jsonpath)
+								}
 								mask = NewUniqueMaskContextCacheEngine(typedCache, mask)
 							default:
 								mask = NewMaskContextCacheEngine(typedCache, mask)
@@ -141,7 +226,7 @@ func BuildPipeline(pipeline Pipeline, conf Definition, caches map[string]Cache)
 						}
 						pipeline = pipeline.Process(NewMaskContextEngineProcess(NewPathSelector(virtualMask.Selector.Jsonpath), mask))
 						nbArg++
-						if i, hasCleaner := mask.(HasCleaner); hasCleaner {
+						if hasCleaner {
 							cleaners = append(cleaners, NewMaskContextEngineProcess(NewPathSelector(virtualMask.Selector.Jsonpath), i.GetCleaner()))
 						}
 					}
@@ -159,6 +244,40 @@ func BuildPipeline(pipeline Pipeline, conf Definition, caches map[string]Cache)
 	return pipeline, caches, nil
 }
 
+// BuildMaskEngine creates the mask of a definition with its cache, for masks applied by another mask on parts of a value
+func BuildMaskEngine(conf Masking, seed int64, caches map[string]Cache) (MaskEngine, error) {
+	for _, factory := range maskFactories {
+		mask, present, err := factory(conf, seed, caches)
+		if err != nil {
+			return nil, errors.New(err.Error() + " for " + conf.Selector.Jsonpath)
+		}
+		if present {
+			registerStateful(conf.Selector.Jsonpath, mask)
+			return cacheMaskEngine(mask, conf, caches)
+		}
+	}
+	return nil, errors.New("No masks defined for " + conf.Selector.Jsonpath)
+}
+
+func cacheMaskEngine(mask MaskEngine, conf Masking, caches map[string]Cache) (MaskEngine, error) {
+	if conf.Cache == "" {
+		return mask, nil
+	}
+	cache, ok := caches[conf.Cache]
+	if !ok {
+		return nil, errors.New("Cache '" + conf.Cache + "' not found for '" + conf.Selector.Jsonpath + "'")
+	}
+	switch typedCache := cache.(type) {
+	case UniqueCache:
+		if conf.Seeder != nil {
+			return nil, errors.New("seeder cannot be used with unique cache '" + conf.Cache + "' for " + conf.Selector.Jsonpath)
+		}
+		return NewUniqueMaskCacheEngine(typedCache, mask), nil
+	default:
+		return NewMaskCacheEngine(typedCache, mask), nil
+	}
+}
+
 func LoadPipelineDefinitionFromYAML(filename string) (Definition, error) {
 	source, err := ioutil.ReadFile(filename)
 	if err != nil {
@@ -18,6 +18,8 @@
 package model
 
 import (
+	"reflect"
+
 	over "github.com/Trendyol/overlog"
 	"github.com/cgi-fr/pimo/pkg/statistics"
 	"github.com/rs/zerolog/log"
@@ -43,21 +45,15 @@ func (mep *MaskEngineProcess) ProcessDictionary(dictionary Dictionary, out Colle
 	defer func() { over.MDC().Remove("path") }()
 	result := CopyDictionary(dictionary)
 	applied := mep.selector.Apply(result, func(rootContext, parentContext Dictionary, key string, value Entry) (Action, Entry) {
-		switch {
-		case value == nil && (mep.preserve == "null" || mep.preserve == "blank"):
-			log.Trace().Msgf("Preserve %s value, skip masking", mep.preserve)
+		if isPreserved(value, mep.preserve) {
 			return NOTHING, nil
-		case value == "" && (mep.preserve == "empty" || mep.preserve == "blank"):
-			log.Trace().Msgf("Preserve %s value, skip masking", mep.preserve)
+		}
+		masked, err := mep.maskValue(value, rootContext, parentContext)
+		if err != nil {
+			ret = err
 			return NOTHING, nil
-		default:
-			masked, err := mep.mask.Mask(value, rootContext, parentContext)
-			if err != nil {
-				ret = err
-				return NOTHING, nil
-			}
-			return WRITE, masked
 		}
+		return WRITE, masked
 	})
 
 	if !applied {
@@ -88,3 +84,39 @@ func (mep *MaskEngineProcess) ProcessDictionary(dictionary Dictionary, out Colle
 
 	return ret
 }
+
+// maskValue masks a value, arrays nested in the selected array are masked element by element
+func (mep *MaskEngineProcess) maskValue(value Entry, context ...Dictionary) (Entry, error) {
+	v := reflect.ValueOf(value)
+	if value == nil || v.Kind() != reflect.Slice {
+		return mep.mask.Mask(value, context...)
+	}
+
+	result := make([]Entry, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		item := v.Index(i).Interface()
+		if isPreserved(item, mep.preserve) {
+			result = append(result, item)
+			continue
+		}
+		masked, err := mep.maskValue(item, context...)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, masked)
+	}
+	return result, nil
+}
+
+func isPreserved(value Entry, preserve string) bool {
+	switch {
+	case value == nil && (preserve == "null" || preserve == "blank"):
+		log.Trace().Msgf("Preserve %s value, skip masking", preserve)
+		return true
+	case value == "" && (preserve == "empty" || preserve == "blank"):
+		log.Trace().Msgf("Preserve %s value, skip masking", preserve)
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"fmt"
+
+	over "github.com/Trendyol/overlog"
+	"github.com/cgi-fr/pimo/pkg/statistics"
+	"github.com/rs/zerolog/log"
+)
+
+// NewMaskKeyEngineProcess creates a processor renaming the keys of the selected objects with the mask
+func NewMaskKeyEngineProcess(selector Selector, mask MaskEngine, preserve string) Processor {
+	return &MaskKeyEngineProcess{selector, mask, preserve}
+}
+
+type MaskKeyEngineProcess struct {
+	selector Selector
+	mask     MaskEngine
+	preserve string
+}
+
+func (mkep *MaskKeyEngineProcess) Open() error {
+	return nil
+}
+
+func (mkep *MaskKeyEngineProcess) ProcessDictionary(dictionary Dictionary, out Collector) (ret error) {
+	over.AddGlobalFields("path")
+	over.MDC().Set("path", mkep.selector)
+	defer func() { over.MDC().Remove("path") }()
+	result := CopyDictionary(dictionary)
+	applied := mkep.selector.Apply(result, func(rootContext, parentContext Dictionary, key string, value Entry) (Action, Entry) {
+		if value == nil {
+			return NOTHING, nil
+		}
+		object, ok := value.(Dictionary)
+		if !ok {
+			ret = fmt.Errorf("can't mask keys of %v, value is not an object", value)
+			return NOTHING, nil
+		}
+		renamed, err := mkep.maskKeys(object, rootContext, parentContext)
+		if err != nil {
+			ret = err
+			return NOTHING, nil
+		}
+		return WRITE, renamed
+	})
+
+	if !applied {
+		statistics.IncIgnoredPathsCount()
+		log.Warn().Msg("Path not found")
+	}
+
+	if ret == nil {
+		out.Collect(result)
+		return
+	}
+
+	if ret != nil && skipLineOnError {
+		log.Warn().AnErr("error", ret).Msg("Line skipped")
+		statistics.IncIgnoredLinesCount()
+		return nil
+	}
+
+	if ret != nil && skipFieldOnError {
+		log.Warn().AnErr("error", ret).Msg("Field skipped")
+		statistics.IncIgnoredFieldsCount()
+		mkep.selector.Apply(result, func(rootContext, parentContext Dictionary, key string, value Entry) (Action, Entry) {
+			return DELETE, nil
+		})
+		out.Collect(result)
+		return nil
+	}
+
+	return ret
+}
+
+// maskKeys returns a copy of the object with masked keys, values and keys order are kept
+func (mkep *MaskKeyEngineProcess) maskKeys(object Dictionary, context ...Dictionary) (Dictionary, error) {
+	result := NewDictionary()
+	iter := object.EntriesIter()
+	for pair, ok := iter(); ok; pair, ok = iter() {
+		key := pair.Key
+		if !isPreserved(key, mkep.preserve) {
+			masked, err := mkep.mask.Mask(key, context...)
+			if err != nil {
+				return result, err
+			}
+			if masked == nil {
+				return result, fmt.Errorf("masked key of %s is null", pair.Key)
+			}
+			key = fmt.Sprint(masked)
+		}
+		if result.Has(key) {
+			return result, fmt.Errorf("masked key %s is duplicated", key)
+		}
+		result.Set(key, pair.Value)
+	}
+	return result, nil
+}
@@ -0,0 +1,152 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/cgi-fr/pimo/pkg/statistics"
+	"github.com/rs/zerolog/log"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// NewValidateProcess create a process checking each dictionary against the JSON schema in the file
+func NewValidateProcess(schemaFile string) (Processor, error) {
+	schema, err := jsonschema.Compile(schemaFile)
+	if err != nil {
+		return nil, err
+	}
+	return &ValidateProcess{schema: schema}, nil
+}
+
+// NewValidateProcessFromString create a process checking each dictionary against the JSON schema
+func NewValidateProcessFromString(schemaContent string) (Processor, error) {
+	schema, err := jsonschema.CompileString("schema.json", schemaContent)
+	if err != nil {
+		return nil, err
+	}
+	return &ValidateProcess{schema: schema}, nil
+}
+
+type ValidateProcess struct {
+	schema *jsonschema.Schema
+}
+
+func (vp *ValidateProcess) Open() error {
+	return nil
+}
+
+func (vp *ValidateProcess) ProcessDictionary(dictionary Dictionary, out Collector) error {
+	ret := vp.validate(dictionary)
+	if ret == nil {
+		out.Collect(dictionary)
+		return nil
+	}
+
+	statistics.IncInvalidLinesCount()
+
+	if skipLineOnError {
+		log.Warn().AnErr("error", ret).Msg("Line skipped")
+		statistics.IncIgnoredLinesCount()
+		return nil
+	}
+
+	if skipFieldOnError {
+		result := CopyDictionary(dictionary)
+		for _, location := range invalidLocations(ret) {
+			if removeLocation(result, location) {
+				log.Warn().AnErr("error", ret).Str("path", location).Msg("Field skipped")
+				statistics.IncIgnoredFieldsCount()
+			}
+		}
+		// removing fields cannot fix every violation (e.g. a missing required field)
+		if ret = vp.validate(result); ret == nil {
+			out.Collect(result)
+			return nil
+		}
+	}
+
+	return ret
+}
+
+// validate checks the dictionary with values decoded as the schema library expects them
+func (vp *ValidateProcess) validate(dictionary Dictionary) error {
+	content, err := json.Marshal(dictionary)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	return vp.schema.Validate(value)
+}
+
+// invalidLocations returns the JSON pointers of values at the origin of the validation error
+func invalidLocations(err error) []string {
+	validationError, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return nil
+	}
+	if len(validationError.Causes) == 0 {
+		return []string{validationError.InstanceLocation}
+	}
+	locations := []string{}
+	for _, cause := range validationError.Causes {
+		locations = append(locations, invalidLocations(cause)...)
+	}
+	return locations
+}
+
+// removeLocation deletes the field targeted by the JSON pointer, an item of an array removes the whole array
+func removeLocation(dictionary Dictionary, location string) bool {
+	if location == "" {
+		return false
+	}
+	tokens := strings.Split(strings.TrimPrefix(location, "/"), "/")
+	current := dictionary
+	for i, token := range tokens {
+		key := strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		value, ok := current.GetValue(key)
+		if !ok {
+			return false
+		}
+		if i == len(tokens)-1 {
+			current.Delete(key)
+			return true
+		}
+		switch typed := value.(type) {
+		case Dictionary:
+			current = typed
+		case []Entry:
+			if _, err := strconv.Atoi(tokens[i+1]); err == nil {
+				current.Delete(key)
+				return true
+			}
+			return false
+		default:
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/cgi-fr/pimo/pkg/statistics"
+	"github.com/stretchr/testify/assert"
+)
+
+const personSchema = `{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string"},
+		"age": {"type": "integer"},
+		"address": {"type": "object", "properties": {"zip": {"type": "string", "pattern": "^[0-9]{5}$"}}}
+	},
+	"required": ["name"]
+}`
+
+func runValidate(t *testing.T, input []Dictionary) ([]Dictionary, error) {
+	processor, err := NewValidateProcessFromString(personSchema)
+	assert.Nil(t, err)
+
+	var result []Dictionary
+	err = NewPipelineFromSlice(input).
+		Process(processor).
+		AddSink(NewSinkToSlice(&result)).
+		Run()
+	return result, err
+}
+
+func TestValidateProcessShouldKeepValidLines(t *testing.T) {
+	statistics.Reset()
+	input := []Dictionary{
+		NewDictionary().With("name", "Bob").With("age", 42).With("address", NewDictionary().With("zip", "44000")),
+	}
+
+	result, err := runValidate(t, input)
+
+	assert.Nil(t, err)
+	assert.Equal(t, input, result)
+	assert.Equal(t, 0, statistics.Compute().GetInvalidLinesCount())
+}
+
+func TestValidateProcessShouldFailOnInvalidLine(t *testing.T) {
+	statistics.Reset()
+	input := []Dictionary{NewDictionary().With("name", "Bob").With("age", "old")}
+
+	_, err := runValidate(t, input)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, statistics.Compute().GetInvalidLinesCount())
+}
+
+func TestValidateProcessShouldSkipInvalidLine(t *testing.T) {
+	InjectConfig(true, false)
+	defer InjectConfig(false, false)
+	statistics.Reset()
+	input := []Dictionary{
+		NewDictionary().With("name", "Bob").With("age", "old"),
+		NewDictionary().With("name", "Tom").With("age", 12),
+	}
+
+	result, err := runValidate(t, input)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []Dictionary{NewDictionary().With("name", "Tom").With("age", 12)}, result)
+	assert.Equal(t, 1, statistics.Compute().GetInvalidLinesCount())
+	assert.Equal(t, 1, statistics.Compute().GetIgnoredLinesCount())
+}
+
+func TestValidateProcessShouldSkipInvalidFields(t *testing.T) {
+	InjectConfig(false, true)
+	defer InjectConfig(false, false)
+	statistics.Reset()
+	input := []Dictionary{
+		NewDictionary().With("name", "Bob").With("age", "old").With("address", NewDictionary().With("zip", "44")),
+	}
+
+	result, err := runValidate(t, input)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []Dictionary{NewDictionary().With("name", "Bob").With("address", NewDictionary())}, result)
+	assert.Equal(t, 1, statistics.Compute().GetInvalidLinesCount())
+	assert.Equal(t, 2, statistics.Compute().GetIgnoredFieldsCount())
+}
+
+func TestValidateProcessShouldFailWhenRemovingFieldsIsNotEnough(t *testing.T) {
+	InjectConfig(false, true)
+	defer InjectConfig(false, false)
+	statistics.Reset()
+	input := []Dictionary{NewDictionary().With("age", 12)}
+
+	_, err := runValidate(t, input)
+
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,104 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"encoding/json"
+	"math/rand"
+)
+
+// RandSource is a seeded source of random numbers that counts draws, its position can be saved and restored
+type RandSource struct {
+	seed  int64
+	draws uint64
+	src   rand.Source64
+}
+
+type randSourceState struct {
+	Seed  int64  `json:"seed"`
+	Draws uint64 `json:"draws"`
+}
+
+func NewRandSource(seed int64) *RandSource {
+	// nolint: gosec
+	return &RandSource{seed, 0, rand.NewSource(seed).(rand.Source64)}
+}
+
+func (s *RandSource) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+func (s *RandSource) Uint64() uint64 {
+	s.draws++
+	return s.src.Uint64()
+}
+
+// Seed reset the source to the beginning of the sequence for the given seed
+func (s *RandSource) Seed(seed int64) {
+	s.seed = seed
+	s.draws = 0
+	s.src.Seed(seed)
+}
+
+// State returns the seed and the number of values drawn from the source
+func (s *RandSource) State() (json.RawMessage, error) {
+	return json.Marshal(randSourceState{s.seed, s.draws})
+}
+
+// Restore reseeds the source and advances it to the saved position
+func (s *RandSource) Restore(state json.RawMessage) error {
+	var saved randSourceState
+	if err := json.Unmarshal(state, &saved); err != nil {
+		return err
+	}
+	s.Seed(saved.Seed)
+	for s.draws < saved.Draws {
+		s.Int63()
+	}
+	return nil
+}
+
+// RandState is embedded in masks drawing their values from a RandSource, it provides the State, Restore and Seed methods of the mask
+type RandState struct {
+	source *RandSource
+}
+
+func NewRandState(source *RandSource) RandState {
+	return RandState{source}
+}
+
+// Source returns the random source of the mask
+func (s RandState) Source() *RandSource {
+	return s.source
+}
+
+// State returns the position of the random generator
+func (s RandState) State() (json.RawMessage, error) {
+	return s.source.State()
+}
+
+// Restore moves the random generator to a saved position
+func (s RandState) Restore(state json.RawMessage) error {
+	return s.source.Restore(state)
+}
+
+// Seed resets the random generator with a new seed
+func (s RandState) Seed(seed int64) {
+	s.source.Seed(seed)
+}
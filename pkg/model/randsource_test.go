@@ -0,0 +1,61 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRandSourceShouldRestorePosition(t *testing.T) {
+	source := NewRandSource(42)
+	// nolint: gosec
+	generator := rand.New(source)
+	for i := 0; i < 10; i++ {
+		generator.Intn(100)
+	}
+	state, err := source.State()
+	assert.Nil(t, err)
+	expected := []int{generator.Intn(100), generator.Intn(100), generator.Intn(100)}
+
+	restored := NewRandSource(0)
+	assert.Nil(t, restored.Restore(state))
+	// nolint: gosec
+	restoredGenerator := rand.New(restored)
+	actual := []int{restoredGenerator.Intn(100), restoredGenerator.Intn(100), restoredGenerator.Intn(100)}
+
+	assert.Equal(t, expected, actual)
+}
+
+func TestRandStateShouldShareSourceOfMask(t *testing.T) {
+	source := NewRandSource(42)
+	mask := struct{ RandState }{NewRandState(source)}
+	// nolint: gosec
+	generator := rand.New(source)
+	first := generator.Intn(100)
+	generator.Intn(100)
+
+	state, err := mask.State()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"seed":42,"draws":2}`, string(state))
+
+	mask.Seed(42)
+	assert.Equal(t, first, generator.Intn(100))
+}
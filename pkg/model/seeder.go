@@ -0,0 +1,131 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/cgi-fr/pimo/pkg/template"
+)
+
+// SeededMaskEngine reseeds a mask before each call, with a seed derived from the masked value or the record
+type SeededMaskEngine struct {
+	original MaskEngine
+	seeder
+}
+
+// NewSeededMaskEngine create a SeededMaskEngine, seed is the base seed combined with the hash of the value
+func NewSeededMaskEngine(original MaskEngine, seed int64, conf SeederType) (SeededMaskEngine, error) {
+	seeder, err := newSeeder(original, seed, conf)
+	return SeededMaskEngine{original, seeder}, err
+}
+
+// Mask reseeds the original mask and delegates masking to it
+func (sme SeededMaskEngine) Mask(e Entry, context ...Dictionary) (Entry, error) {
+	if err := sme.reseed(e, context...); err != nil {
+		return nil, err
+	}
+	return sme.original.Mask(e, context...)
+}
+
+// SeededMaskContextEngine reseeds a context mask before each call, with a seed derived from the masked value or the record
+type SeededMaskContextEngine struct {
+	original MaskContextEngine
+	seeder
+}
+
+// NewSeededMaskContextEngine create a SeededMaskContextEngine, seed is the base seed combined with the hash of the value
+func NewSeededMaskContextEngine(original MaskContextEngine, seed int64, conf SeederType) (SeededMaskContextEngine, error) {
+	seeder, err := newSeeder(original, seed, conf)
+	return SeededMaskContextEngine{original, seeder}, err
+}
+
+// MaskContext reseeds the original mask and delegates masking to it
+func (smce SeededMaskContextEngine) MaskContext(e Dictionary, key string, context ...Dictionary) (Dictionary, error) {
+	value, _ := e.GetValue(key)
+	if err := smce.reseed(value, context...); err != nil {
+		return e, err
+	}
+	return smce.original.MaskContext(e, key, context...)
+}
+
+type seeder struct {
+	seedable Seedable
+	seed     int64
+	field    Selector
+	template *template.Engine
+}
+
+func newSeeder(original interface{}, seed int64, conf SeederType) (seeder, error) {
+	seedable, ok := original.(Seedable)
+	if !ok {
+		return seeder{}, fmt.Errorf("seeder is not supported by this mask")
+	}
+	if conf.Field != "" && conf.Template != "" {
+		return seeder{}, fmt.Errorf("seeder accepts either a field or a template")
+	}
+	result := seeder{seedable, seed, nil, nil}
+	if conf.Field != "" {
+		result.field = NewPathSelector(conf.Field)
+	}
+	if conf.Template != "" {
+		temp, err := template.NewEngine(conf.Template)
+		if err != nil {
+			return result, err
+		}
+		result.template = temp
+	}
+	return result, nil
+}
+
+// reseed sets the seed of the mask from the hash of the value, the field or the template
+func (s seeder) reseed(e Entry, context ...Dictionary) error {
+	if len(context) == 0 {
+		context = []Dictionary{NewDictionary()}
+	}
+	var key []byte
+	switch {
+	case s.template != nil:
+		var output bytes.Buffer
+		if err := s.template.Execute(&output, context[0].Unordered()); err != nil {
+			return err
+		}
+		key = output.Bytes()
+	case s.field != nil:
+		value, _ := s.field.Read(context[0])
+		b, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		key = b
+	default:
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		key = b
+	}
+
+	h := fnv.New64a()
+	h.Write(key)
+	s.seedable.Seed(s.seed + int64(h.Sum64()))
+	return nil
+}
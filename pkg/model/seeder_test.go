@@ -0,0 +1,101 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type randomMask struct {
+	source *RandSource
+	rand   *rand.Rand
+}
+
+func newRandomMask() randomMask {
+	source := NewRandSource(0)
+	// nolint: gosec
+	return randomMask{source, rand.New(source)}
+}
+
+func (m randomMask) Mask(e Entry, context ...Dictionary) (Entry, error) {
+	return m.rand.Intn(1000000), nil
+}
+
+func (m randomMask) Seed(seed int64) {
+	m.source.Seed(seed)
+}
+
+func TestSeededMaskShouldReturnSameOutputForSameValue(t *testing.T) {
+	mask, err := NewSeededMaskEngine(newRandomMask(), 42, SeederType{})
+	assert.Nil(t, err)
+
+	first, _ := mask.Mask("Alice")
+	other, _ := mask.Mask("Bob")
+	second, _ := mask.Mask("Alice")
+
+	assert.Equal(t, first, second)
+	assert.NotEqual(t, first, other)
+}
+
+func TestSeededMaskShouldUseField(t *testing.T) {
+	mask, err := NewSeededMaskEngine(newRandomMask(), 42, SeederType{Field: "id"})
+	assert.Nil(t, err)
+
+	first, _ := mask.Mask("Alice", NewDictionary().With("id", 1))
+	second, _ := mask.Mask("Bob", NewDictionary().With("id", 1))
+
+	assert.Equal(t, first, second)
+}
+
+func TestSeededMaskShouldUseTemplate(t *testing.T) {
+	mask, err := NewSeededMaskEngine(newRandomMask(), 42, SeederType{Template: "{{.id}}"})
+	assert.Nil(t, err)
+
+	first, _ := mask.Mask("Alice", NewDictionary().With("id", 1))
+	second, _ := mask.Mask("Bob", NewDictionary().With("id", 1))
+	other, _ := mask.Mask("Alice", NewDictionary().With("id", 2))
+
+	assert.Equal(t, first, second)
+	assert.NotEqual(t, first, other)
+}
+
+func TestSeededMaskShouldRequireSeedableMask(t *testing.T) {
+	_, err := NewSeededMaskEngine(FunctionMaskEngine{func(e Entry, c ...Dictionary) (Entry, error) { return e, nil }}, 42, SeederType{})
+	assert.NotNil(t, err)
+}
+
+func (m randomMask) MaskContext(e Dictionary, key string, context ...Dictionary) (Dictionary, error) {
+	e.Set(key, m.rand.Intn(1000000))
+	return e, nil
+}
+
+func TestSeededMaskContextShouldUseField(t *testing.T) {
+	mask, err := NewSeededMaskContextEngine(newRandomMask(), 42, SeederType{Field: "id"})
+	assert.Nil(t, err)
+
+	record := NewDictionary().With("id", 1)
+	first, _ := mask.MaskContext(record.Copy(), "value", record)
+	other, _ := mask.MaskContext(NewDictionary().With("id", 2), "value", NewDictionary().With("id", 2))
+	second, _ := mask.MaskContext(record.Copy(), "value", record)
+
+	assert.Equal(t, first.Get("value"), second.Get("value"))
+	assert.NotEqual(t, first.Get("value"), other.Get("value"))
+}
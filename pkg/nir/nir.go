@@ -0,0 +1,139 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package nir
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cgi-fr/pimo/pkg/insee"
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// MaskEngine is a mask to generate french social security numbers (NIR) with a valid key
+type MaskEngine struct {
+	rand *rand.Rand
+	model.RandState
+	genderField     string
+	birthDateField  string
+	birthPlaceField string
+}
+
+// NewMask create a MaskEngine, sex, birth date and birth place are read from fields of the context when given
+func NewMask(genderField, birthDateField, birthPlaceField string, seed int64) MaskEngine {
+	source := model.NewRandSource(seed)
+	// nolint: gosec
+	return MaskEngine{rand.New(source), model.NewRandState(source), genderField, birthDateField, birthPlaceField}
+}
+
+// Mask returns a new NIR of 15 digits
+func (me MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.Entry, error) {
+	log.Info().Msg("Mask nir")
+
+	if len(context) == 0 {
+		context = []model.Dictionary{model.NewDictionary()}
+	}
+
+	sex, ok := Sex(field(context[0], me.genderField))
+	if !ok {
+		sex = 1 + me.rand.Intn(2)
+	}
+
+	birthDate, ok := Date(field(context[0], me.birthDateField))
+	if !ok {
+		birthDate = time.Date(1930+me.rand.Intn(80), time.Month(1+me.rand.Intn(12)), 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	birthPlace := ""
+	if place, ok := field(context[0], me.birthPlaceField).(string); ok {
+		if _, valid := insee.Department(place); valid || (len(place) == 5 && strings.HasPrefix(place, "99")) {
+			birthPlace = strings.ToUpper(place)
+		}
+	}
+	if birthPlace == "" {
+		birthPlace = insee.Commune(me.rand, "")
+	}
+
+	nir := fmt.Sprintf("%d%02d%02d%s%03d", sex, birthDate.Year()%100, int(birthDate.Month()), birthPlace, 1+me.rand.Intn(999))
+	return nir + Key(nir), nil
+}
+
+// Key computes the two digits key of the 13 first characters of a NIR,
+// departments 2A and 2B of Corsica are replaced by 19 and 18
+func Key(nir string) string {
+	number := strings.NewReplacer("2A", "19", "2B", "18").Replace(strings.ToUpper(nir[:13]))
+	value, err := strconv.ParseInt(number, 10, 64)
+	if err != nil {
+		return "00"
+	}
+	return fmt.Sprintf("%02d", 97-value%97)
+}
+
+// Sex returns 1 for a male and 2 for a female from a gender value, the whole value must be a known gender or title
+func Sex(gender model.Entry) (int, bool) {
+	if gender == nil {
+		return 0, false
+	}
+	value := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(fmt.Sprint(gender))), ".")
+	switch value {
+	case "1", "m", "male", "man", "h", "homme", "mr", "monsieur":
+		return 1, true
+	case "2", "f", "female", "woman", "w", "femme", "mme", "madame", "mlle", "mademoiselle", "mrs", "ms", "miss":
+		return 2, true
+	}
+	return 0, false
+}
+
+// Date returns a date from a time value or a string with RFC 3339 or YYYY-MM-DD format
+func Date(value model.Entry) (time.Time, bool) {
+	switch date := value.(type) {
+	case time.Time:
+		return date, true
+	case string:
+		for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+			if result, err := time.Parse(layout, date); err == nil {
+				return result, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func field(dict model.Dictionary, name string) model.Entry {
+	if name == "" {
+		return nil
+	}
+	return dict.Get(name)
+}
+
+// Factory create a mask from a yaml config
+func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (model.MaskEngine, bool, error) {
+	if conf.Mask.NIR != nil {
+		// set differents seeds for differents jsonpath
+		h := fnv.New64a()
+		h.Write([]byte(conf.Selector.Jsonpath))
+		seed += int64(h.Sum64())
+		return NewMask(conf.Mask.NIR.GenderField, conf.Mask.NIR.BirthDateField, conf.Mask.NIR.BirthPlaceField, seed), true, nil
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,79 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+package nir
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "91", Key("1850578006084"))
+	assert.Equal(t, "35", Key("185052A006084"))
+}
+
+func TestMaskingShouldBeCoherentWithContext(t *testing.T) {
+	mask := NewMask("gender", "birthdate", "birthplace", 42)
+	context := model.NewDictionary().
+		With("gender", "F").
+		With("birthdate", "1985-05-17").
+		With("birthplace", "2A004")
+
+	result, err := mask.Mask("185057800608491", context)
+	assert.Nil(t, err)
+	nir := result.(string)
+	assert.Regexp(t, `^285052A004[0-9]{5}$`, nir)
+	assert.Equal(t, Key(nir), nir[13:])
+}
+
+func TestMaskingShouldGenerateRandomNIR(t *testing.T) {
+	mask := NewMask("", "", "", 42)
+	for i := 0; i < 100; i++ {
+		result, err := mask.Mask("")
+		assert.Nil(t, err)
+		nir := result.(string)
+		assert.Regexp(t, `^[12][0-9]{2}(0[1-9]|1[0-2])[0-9AB]{10}$`, nir)
+		assert.Equal(t, Key(nir), nir[13:])
+	}
+}
+
+func TestSexAndDate(t *testing.T) {
+	sex, ok := Sex("Male")
+	assert.True(t, ok)
+	assert.Equal(t, 1, sex)
+	sex, ok = Sex(2)
+	assert.True(t, ok)
+	assert.Equal(t, 2, sex)
+	_, ok = Sex(nil)
+	assert.False(t, ok)
+	for _, female := range []string{"Mme", "Madame", "Mlle", "F"} {
+		sex, ok = Sex(female)
+		assert.True(t, ok)
+		assert.Equal(t, 2, sex, female)
+	}
+	_, ok = Sex("Martin")
+	assert.False(t, ok)
+
+	date, ok := Date("2001-12-31T10:00:00Z")
+	assert.True(t, ok)
+	assert.Equal(t, time.December, date.Month())
+	_, ok = Date("31/12/2001")
+	assert.False(t, ok)
+}
@@ -0,0 +1,234 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package phone
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	Mobile   = "mobile"
+	Landline = "landline"
+
+	E164          = "e164"
+	National      = "national"
+	International = "international"
+)
+
+// MaskEngine is a mask to generate phone numbers following national numbering plans
+type MaskEngine struct {
+	rand *rand.Rand
+	model.RandState
+	country     string
+	kind        string
+	format      string
+	keepCountry bool
+	keepType    bool
+}
+
+// NewMask create a MaskEngine, kind is mobile, landline or empty for any, format is e164, national or international
+func NewMask(country, kind, format string, keepCountry, keepType bool, seed int64) (MaskEngine, error) {
+	country = normalizeCountry(country)
+	if country == "" {
+		country = "FR"
+	}
+	if _, ok := plans[country]; !ok {
+		return MaskEngine{}, fmt.Errorf("country '%s' is not supported", country)
+	}
+	switch kind {
+	case "", Mobile, Landline:
+	default:
+		return MaskEngine{}, fmt.Errorf("type '%s' is not supported, use %s or %s", kind, Mobile, Landline)
+	}
+	switch format {
+	case "":
+		format = E164
+	case E164, National, International:
+	default:
+		return MaskEngine{}, fmt.Errorf("format '%s' is not supported, use %s, %s or %s", format, E164, National, International)
+	}
+	source := model.NewRandSource(seed)
+	// nolint: gosec
+	return MaskEngine{rand.New(source), model.NewRandState(source), country, kind, format, keepCountry, keepType}, nil
+}
+
+// Mask returns a new phone number
+func (me MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.Entry, error) {
+	log.Info().Msg("Mask phone")
+
+	country, kind := me.country, me.kind
+	if original, ok := e.(string); ok && (me.keepCountry || me.keepType) {
+		originalCountry, originalKind := Parse(original, me.country)
+		if me.keepCountry && originalCountry != "" {
+			country = originalCountry
+		}
+		if me.keepType && originalKind != "" {
+			kind = originalKind
+		}
+	}
+
+	return me.generate(plans[country], kind), nil
+}
+
+func (me MaskEngine) generate(p plan, kind string) string {
+	patterns := p.mobile
+	switch {
+	case kind == Landline, kind == "" && me.rand.Intn(2) == 0:
+		patterns = p.landline
+	}
+	pat := patterns[me.rand.Intn(len(patterns))]
+
+	display := pat.national
+	if pat.international != "" && me.format != National {
+		display = pat.international
+	}
+
+	var number strings.Builder
+	for _, c := range display {
+		switch c {
+		case 'X':
+			number.WriteByte(byte('0' + me.rand.Intn(10)))
+		case 'N':
+			number.WriteByte(byte('2' + me.rand.Intn(8)))
+		default:
+			number.WriteRune(c)
+		}
+	}
+
+	switch me.format {
+	case National:
+		return p.trunk + number.String()
+	case International:
+		return "+" + p.code + " " + number.String()
+	default:
+		return "+" + p.code + digits(number.String())
+	}
+}
+
+// Parse returns the country and the type of a phone number, a number without country code is read with the default country
+func Parse(number string, defaultCountry string) (string, string) {
+	trimmed := strings.TrimSpace(number)
+	num := digits(trimmed)
+
+	country := ""
+	switch {
+	case strings.HasPrefix(trimmed, "+"):
+		country, num = matchCountryCode(num)
+	case strings.HasPrefix(num, "00"):
+		country, num = matchCountryCode(num[2:])
+	default:
+		country = normalizeCountry(defaultCountry)
+		if p, ok := plans[country]; ok {
+			num = strings.TrimPrefix(num, p.trunk)
+		}
+	}
+
+	p, ok := plans[country]
+	if !ok {
+		return "", ""
+	}
+	mobile, landline := matchAny(p.mobile, num), matchAny(p.landline, num)
+	switch {
+	case mobile && !landline:
+		return country, Mobile
+	case landline && !mobile:
+		return country, Landline
+	default:
+		// unknown or ambiguous (e.g. US numbers)
+		return country, ""
+	}
+}
+
+func matchAny(patterns []pattern, num string) bool {
+	for _, pat := range patterns {
+		if match(pat, num) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchCountryCode(num string) (string, string) {
+	found, foundCode := "", ""
+	for country, p := range plans {
+		if strings.HasPrefix(num, p.code) && len(p.code) > len(foundCode) {
+			found, foundCode = country, p.code
+		}
+	}
+	return found, strings.TrimPrefix(num, foundCode)
+}
+
+func match(pat pattern, num string) bool {
+	placeholders := digits(pat.national)
+	if len(placeholders) != len(num) {
+		return false
+	}
+	for i := 0; i < len(num); i++ {
+		switch placeholders[i] {
+		case 'X':
+		case 'N':
+			if num[i] < '2' {
+				return false
+			}
+		default:
+			if placeholders[i] != num[i] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// digits keeps only digits and placeholders from a string
+func digits(s string) string {
+	var sb strings.Builder
+	for _, c := range s {
+		if (c >= '0' && c <= '9') || c == 'X' || c == 'N' {
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+func normalizeCountry(country string) string {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if alias, ok := aliases[country]; ok {
+		return alias
+	}
+	return country
+}
+
+// Factory create a mask from a yaml config
+func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (model.MaskEngine, bool, error) {
+	if conf.Mask.Phone != nil {
+		// set differents seeds for differents jsonpath
+		h := fnv.New64a()
+		h.Write([]byte(conf.Selector.Jsonpath))
+		seed += int64(h.Sum64())
+		phone := conf.Mask.Phone
+		mask, err := NewMask(phone.Country, phone.Type, phone.Format, phone.KeepCountry, phone.KeepType, seed)
+		return mask, true, err
+	}
+	return nil, false, nil
+}
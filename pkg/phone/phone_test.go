@@ -0,0 +1,114 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package phone
+
+import (
+	"testing"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskingShouldGenerateFrenchMobileE164(t *testing.T) {
+	mask, err := NewMask("FR", Mobile, "", false, false, 42)
+	assert.Nil(t, err)
+
+	for i := 0; i < 100; i++ {
+		result, err := mask.Mask("+33612345678")
+		assert.Nil(t, err)
+		assert.Regexp(t, `^\+33[67][0-9]{8}$`, result)
+	}
+}
+
+func TestMaskingShouldFormatNumbers(t *testing.T) {
+	national, err := NewMask("FR", Landline, National, false, false, 42)
+	assert.Nil(t, err)
+	result, err := national.Mask("")
+	assert.Nil(t, err)
+	assert.Regexp(t, `^0[1-5]( [0-9]{2}){4}$`, result)
+
+	international, err := NewMask("US", "", International, false, false, 42)
+	assert.Nil(t, err)
+	result, err = international.Mask("")
+	assert.Nil(t, err)
+	assert.Regexp(t, `^\+1 [2-9][0-9]{2}-[2-9][0-9]{2}-[0-9]{4}$`, result)
+}
+
+func TestMaskingShouldKeepCountryAndType(t *testing.T) {
+	mask, err := NewMask("FR", "", E164, true, true, 42)
+	assert.Nil(t, err)
+
+	for i := 0; i < 20; i++ {
+		result, err := mask.Mask("+44 20 7946 0958")
+		assert.Nil(t, err)
+		assert.Regexp(t, `^\+44(20[2-9][0-9]{7}|1[236]1[2-9][0-9]{6})$`, result)
+
+		result, err = mask.Mask("06 12 34 56 78")
+		assert.Nil(t, err)
+		assert.Regexp(t, `^\+33[67][0-9]{8}$`, result)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		number  string
+		country string
+		kind    string
+	}{
+		{"+33 6 12 34 56 78", "FR", Mobile},
+		{"0033145678901", "FR", Landline},
+		{"01 45 67 89 01", "FR", Landline},
+		{"+32 470 12 34 56", "BE", Mobile},
+		{"+1 (212) 555-0100", "US", ""},
+		{"+49 30 21234567", "DE", Landline},
+		{"+999 123", "", ""},
+	}
+	for _, tt := range tests {
+		country, kind := Parse(tt.number, "FR")
+		assert.Equal(t, tt.country, country, tt.number)
+		assert.Equal(t, tt.kind, kind, tt.number)
+	}
+}
+
+func TestMaskingShouldBeReproducible(t *testing.T) {
+	first, err := NewMask("DE", "", "", false, false, 42)
+	assert.Nil(t, err)
+	second, err := NewMask("DE", "", "", false, false, 42)
+	assert.Nil(t, err)
+
+	for i := 0; i < 10; i++ {
+		a, _ := first.Mask("")
+		b, _ := second.Mask("")
+		assert.Equal(t, a, b)
+	}
+}
+
+func TestFactoryShouldRejectUnknownCountry(t *testing.T) {
+	conf := model.Masking{Mask: model.MaskType{Phone: &model.PhoneType{Country: "ZZ"}}}
+	_, present, err := Factory(conf, 0, nil)
+	assert.True(t, present)
+	assert.NotNil(t, err)
+
+	conf = model.Masking{Mask: model.MaskType{Phone: &model.PhoneType{Country: "gb", Format: "national"}}}
+	mask, present, err := Factory(conf, 0, nil)
+	assert.True(t, present)
+	assert.Nil(t, err)
+	result, err := mask.Mask("")
+	assert.Nil(t, err)
+	assert.Regexp(t, `^0`, result)
+}
@@ -0,0 +1,141 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package phone
+
+// pattern describes numbers of a numbering plan, digits are generated in place of placeholders :
+// X is any digit, N is a digit from 2 to 9, other characters are kept as is.
+// The national pattern is displayed after the trunk prefix, the international pattern after the country code.
+type pattern struct {
+	national      string
+	international string
+}
+
+// plan is a simplified national numbering plan
+type plan struct {
+	code     string
+	trunk    string
+	mobile   []pattern
+	landline []pattern
+}
+
+// nolint: gochecknoglobals
+var plans = map[string]plan{
+	"FR": {
+		code:  "33",
+		trunk: "0",
+		mobile: []pattern{
+			{"6 XX XX XX XX", ""},
+			{"7 XX XX XX XX", ""},
+		},
+		landline: []pattern{
+			{"1 XX XX XX XX", ""},
+			{"2 XX XX XX XX", ""},
+			{"3 XX XX XX XX", ""},
+			{"4 XX XX XX XX", ""},
+			{"5 XX XX XX XX", ""},
+		},
+	},
+	"BE": {
+		code:  "32",
+		trunk: "0",
+		mobile: []pattern{
+			{"46X XX XX XX", ""},
+			{"47X XX XX XX", ""},
+			{"48X XX XX XX", ""},
+			{"49X XX XX XX", ""},
+		},
+		landline: []pattern{
+			{"2 NXX XX XX", ""},
+			{"3 NXX XX XX", ""},
+			{"4 NXX XX XX", ""},
+			{"9 NXX XX XX", ""},
+			{"NX XX XX XX", ""},
+		},
+	},
+	"DE": {
+		code:  "49",
+		trunk: "0",
+		mobile: []pattern{
+			{"151 NXXXXXXX", ""},
+			{"160 NXXXXXX", ""},
+			{"170 NXXXXXX", ""},
+			{"176 NXXXXXXX", ""},
+		},
+		landline: []pattern{
+			{"30 NXXXXXXX", ""},
+			{"40 NXXXXXXX", ""},
+			{"69 NXXXXXXX", ""},
+			{"89 NXXXXXXX", ""},
+			{"221 NXXXXXX", ""},
+		},
+	},
+	"UK": {
+		code:  "44",
+		trunk: "0",
+		mobile: []pattern{
+			{"7NXX XXXXXX", ""},
+		},
+		landline: []pattern{
+			{"20 NXXX XXXX", ""},
+			{"121 NXX XXXX", ""},
+			{"131 NXX XXXX", ""},
+			{"161 NXX XXXX", ""},
+		},
+	},
+	"US": {
+		code:  "1",
+		trunk: "",
+		mobile: []pattern{
+			{"(NXX) NXX-XXXX", "NXX-NXX-XXXX"},
+		},
+		landline: []pattern{
+			{"(NXX) NXX-XXXX", "NXX-NXX-XXXX"},
+		},
+	},
+	"ES": {
+		code:  "34",
+		trunk: "",
+		mobile: []pattern{
+			{"6XX XX XX XX", ""},
+			{"7NX XX XX XX", ""},
+		},
+		landline: []pattern{
+			{"9NX XX XX XX", ""},
+			{"8NX XX XX XX", ""},
+		},
+	},
+	"IT": {
+		code:  "39",
+		trunk: "",
+		mobile: []pattern{
+			{"3NX XXX XXXX", ""},
+		},
+		landline: []pattern{
+			{"06 NXXX XXXX", ""},
+			{"02 NXXX XXXX", ""},
+			{"011 NXX XXXX", ""},
+			{"081 NXX XXXX", ""},
+		},
+	},
+}
+
+// aliases of country codes
+// nolint: gochecknoglobals
+var aliases = map[string]string{
+	"GB": "UK",
+}
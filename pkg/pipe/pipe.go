@@ -18,12 +18,17 @@
 package pipe
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"hash/fnv"
+	"math/rand"
 	"regexp"
+	"sort"
 
 	over "github.com/Trendyol/overlog"
 	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/cgi-fr/pimo/pkg/template"
 	"github.com/rs/zerolog/log"
 )
 
@@ -33,6 +38,20 @@ type MaskEngine struct {
 	pipeline     model.Pipeline
 	injectParent string
 	injectRoot   string
+	transform    transformation
+}
+
+// transformation changes the elements of the array before the nested pipeline, elements are filtered,
+// sorted, truncated and then repeated
+type transformation struct {
+	repeat     int
+	filter     *template.Engine
+	sortBy     model.Selector
+	descending bool
+	limit      int
+	sample     int
+	source     *model.RandSource
+	rand       *rand.Rand
 }
 
 // NewMask return a MaskEngine from a value
@@ -42,7 +61,7 @@ func NewMask(seed int64, injectParent string, injectRoot string, caches map[stri
 	if len(filename) > 0 {
 		definition, err = model.LoadPipelineDefinitionFromYAML(filename)
 		if err != nil {
-			return MaskEngine{filename, nil, injectParent, injectRoot}, err
+			return MaskEngine{filename, nil, injectParent, injectRoot, transformation{}}, err
 		}
 		// merge the current seed with the seed provided by configuration on the pipe
 		definition.Seed += seed
@@ -51,7 +70,48 @@ func NewMask(seed int64, injectParent string, injectRoot string, caches map[stri
 	}
 	pipeline := model.NewPipeline(nil)
 	pipeline, _, err = model.BuildPipeline(pipeline, definition, caches)
-	return MaskEngine{"", pipeline, injectParent, injectRoot}, err
+	return MaskEngine{"", pipeline, injectParent, injectRoot, transformation{}}, err
+}
+
+// NewMaskFromConfig return a MaskEngine from a pipe configuration, with the transformations of the array
+func NewMaskFromConfig(conf model.PipeType, seed int64, caches map[string]model.Cache) (MaskEngine, error) {
+	mask, err := NewMask(seed, conf.InjectParent, conf.InjectRoot, caches, conf.DefinitionFile, conf.Masking...)
+	if err != nil {
+		return mask, err
+	}
+	if conf.Repeat < 0 || conf.Limit < 0 || conf.Sample < 0 {
+		return mask, fmt.Errorf("repeat, limit and sample should be positive")
+	}
+	if conf.Limit > 0 && conf.Sample > 0 {
+		return mask, fmt.Errorf("limit and sample cannot be used together")
+	}
+	if conf.Order != "" && conf.Order != "asc" && conf.Order != "desc" {
+		return mask, fmt.Errorf("order should be asc or desc, not '%s'", conf.Order)
+	}
+	if conf.Order != "" && conf.SortBy == "" {
+		return mask, fmt.Errorf("order cannot be used without sortBy")
+	}
+
+	source := model.NewRandSource(seed)
+	// nolint: gosec
+	mask.transform = transformation{
+		repeat:     conf.Repeat,
+		descending: conf.Order == "desc",
+		limit:      conf.Limit,
+		sample:     conf.Sample,
+		source:     source,
+		rand:       rand.New(source),
+	}
+	if conf.Filter != "" {
+		mask.transform.filter, err = template.NewEngine(conf.Filter)
+		if err != nil {
+			return mask, err
+		}
+	}
+	if conf.SortBy != "" {
+		mask.transform.sortBy = model.NewPathSelector(conf.SortBy)
+	}
+	return mask, nil
 }
 
 func (me MaskEngine) MaskContext(e model.Dictionary, key string, context ...model.Dictionary) (model.Dictionary, error) {
@@ -76,6 +136,10 @@ func (me MaskEngine) MaskContext(e model.Dictionary, key string, context ...mode
 		}
 		input = append(input, elemInput)
 	}
+	input, err := me.transform.apply(input)
+	if err != nil {
+		return model.NewDictionary(), err
+	}
 	saveConfig, _ := over.MDC().Get("config")
 	savePath, _ := over.MDC().Get("path")
 	saveContext, _ := over.MDC().Get("context")
@@ -89,7 +153,7 @@ func (me MaskEngine) MaskContext(e model.Dictionary, key string, context ...mode
 	//			Process(me.pipeline).
 	//			AddSink(model.NewSinkToSlice(&result)).
 	//			Run()
-	err := me.pipeline.
+	err = me.pipeline.
 		WithSource(model.NewSourceFromSlice(input)).
 		Process(model.NewCounterProcessWithCallback("internal", 1, updateContext)).
 		AddSink(model.NewSinkToSlice(&result)).
@@ -114,12 +178,12 @@ func (me MaskEngine) MaskContext(e model.Dictionary, key string, context ...mode
 
 // Factory create a mask from a configuration
 func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (model.MaskContextEngine, bool, error) {
-	if len(conf.Mask.Pipe.Masking) > 0 || len(conf.Mask.Pipe.DefinitionFile) > 0 {
+	if len(conf.Mask.Pipe.Masking) > 0 || len(conf.Mask.Pipe.DefinitionFile) > 0 || hasTransformation(conf.Mask.Pipe) {
 		// set differents seeds for differents jsonpath
 		h := fnv.New64a()
 		h.Write([]byte(conf.Selector.Jsonpath))
 		seed += int64(h.Sum64())
-		mask, err := NewMask(seed, conf.Mask.Pipe.InjectParent, conf.Mask.Pipe.InjectRoot, caches, conf.Mask.Pipe.DefinitionFile, conf.Mask.Pipe.Masking...)
+		mask, err := NewMaskFromConfig(conf.Mask.Pipe, seed, caches)
 		if err != nil {
 			return mask, true, err
 		}
@@ -128,6 +192,129 @@ func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (mod
 	return nil, false, nil
 }
 
+func hasTransformation(conf model.PipeType) bool {
+	return conf.Repeat > 0 || conf.Filter != "" || conf.SortBy != "" || conf.Limit > 0 || conf.Sample > 0
+}
+
+// apply filters, sorts, truncates and repeats the elements given to the nested pipeline
+func (t transformation) apply(elements []model.Dictionary) ([]model.Dictionary, error) {
+	if t.filter != nil {
+		kept := []model.Dictionary{}
+		for _, element := range elements {
+			var output bytes.Buffer
+			if err := t.filter.Execute(&output, element.Unordered()); err != nil {
+				return nil, err
+			}
+			if output.String() == "true" {
+				kept = append(kept, element)
+			}
+		}
+		elements = kept
+	}
+
+	if t.sortBy != nil {
+		sort.SliceStable(elements, func(i, j int) bool {
+			a, _ := t.sortBy.Read(elements[i])
+			b, _ := t.sortBy.Read(elements[j])
+			if t.descending {
+				return less(b, a)
+			}
+			return less(a, b)
+		})
+	}
+
+	if t.sample > 0 && len(elements) > t.sample {
+		// sampled elements keep their order
+		indexes := t.rand.Perm(len(elements))[:t.sample]
+		sort.Ints(indexes)
+		sampled := make([]model.Dictionary, len(indexes))
+		for i, index := range indexes {
+			sampled[i] = elements[index]
+		}
+		elements = sampled
+	}
+
+	if t.limit > 0 && len(elements) > t.limit {
+		elements = elements[:t.limit]
+	}
+
+	if t.repeat > 1 {
+		repeated := make([]model.Dictionary, 0, len(elements)*t.repeat)
+		for _, element := range elements {
+			repeated = append(repeated, element)
+			for i := 1; i < t.repeat; i++ {
+				repeated = append(repeated, model.CopyDictionary(element))
+			}
+		}
+		elements = repeated
+	}
+	return elements, nil
+}
+
+// less orders missing values first, then numbers by value, strings by text and other values by their text
+func less(a, b model.Entry) bool {
+	rankA, rankB := rank(a), rank(b)
+	if rankA != rankB {
+		return rankA < rankB
+	}
+	switch rankA {
+	case 0:
+		return false
+	case 1:
+		x, _ := toFloat(a)
+		y, _ := toFloat(b)
+		return x < y
+	default:
+		return fmt.Sprint(a) < fmt.Sprint(b)
+	}
+}
+
+// rank gives the order of the types of values : missing values, numbers, strings, then other values
+func rank(value model.Entry) int {
+	if value == nil {
+		return 0
+	}
+	if _, ok := toFloat(value); ok {
+		return 1
+	}
+	if _, ok := value.(string); ok {
+		return 2
+	}
+	return 3
+}
+
+func toFloat(value model.Entry) (float64, bool) {
+	switch typed := value.(type) {
+	case json.Number:
+		f, err := typed.Float64()
+		return f, err == nil
+	case int:
+		return float64(typed), true
+	case int64:
+		return float64(typed), true
+	case float64:
+		return typed, true
+	default:
+		return 0, false
+	}
+}
+
+// State returns the position of the random generator used to sample elements
+func (me MaskEngine) State() (json.RawMessage, error) {
+	if me.transform.source == nil {
+		return nil, nil
+	}
+	return me.transform.source.State()
+}
+
+// Restore moves the random generator used to sample elements to a saved position
+func (me MaskEngine) Restore(state json.RawMessage) error {
+	if me.transform.source == nil {
+		return nil
+	}
+	return me.transform.source.Restore(state)
+}
+
 var re = regexp.MustCompile(`(\[\d*\])?$`)
 
 func updateContext(counter int) {
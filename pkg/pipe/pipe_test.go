@@ -78,3 +78,111 @@ func DictionariesToJSONLine(dictionaries []model.Dictionary) string {
 	}
 	return strings.TrimSuffix(string(jsonline), "\n")
 }
+
+func transactions() model.Dictionary {
+	return model.NewDictionary().With("transactions", []model.Entry{
+		model.NewDictionary().With("date", "2020-01-01").With("amount", 5),
+		model.NewDictionary().With("date", "2021-03-01").With("amount", 50),
+		model.NewDictionary().With("date", "2019-01-01").With("amount", 15),
+		model.NewDictionary().With("date", "2022-01-01").With("amount", 12),
+	})
+}
+
+func dates(dict model.Dictionary) []model.Entry {
+	result := []model.Entry{}
+	for _, transaction := range dict.Get("transactions").([]model.Dictionary) {
+		result = append(result, transaction.Get("date"))
+	}
+	return result
+}
+
+func TestMaskEngineShouldFilterSortAndLimit(t *testing.T) {
+	mask, err := pipe.NewMaskFromConfig(model.PipeType{
+		Filter: `{{ ge .date "2020-01-01" }}`,
+		SortBy: "date",
+		Order:  "desc",
+		Limit:  2,
+	}, 42, map[string]model.Cache{})
+	assert.Nil(t, err)
+
+	result, err := mask.MaskContext(transactions(), "transactions")
+	assert.Nil(t, err)
+	assert.Equal(t, []model.Entry{"2022-01-01", "2021-03-01"}, dates(result))
+}
+
+func TestMaskEngineShouldSortNumbers(t *testing.T) {
+	mask, err := pipe.NewMaskFromConfig(model.PipeType{SortBy: "amount"}, 42, map[string]model.Cache{})
+	assert.Nil(t, err)
+
+	result, err := mask.MaskContext(transactions(), "transactions")
+	assert.Nil(t, err)
+	assert.Equal(t, []model.Entry{"2020-01-01", "2022-01-01", "2019-01-01", "2021-03-01"}, dates(result))
+}
+
+func TestMaskEngineShouldSortMixedTypes(t *testing.T) {
+	mask, err := pipe.NewMaskFromConfig(model.PipeType{SortBy: "v"}, 42, map[string]model.Cache{})
+	assert.Nil(t, err)
+
+	values := []model.Entry{"9", true, 10, nil, "10", json.Number("9"), 2.5}
+	expected := []model.Entry{nil, 2.5, json.Number("9"), 10, "10", "9", true}
+	// the result does not depend on the order of the input
+	for i := 0; i < len(values); i++ {
+		elements := []model.Entry{}
+		for j := range values {
+			elements = append(elements, model.NewDictionary().With("v", values[(i+j)%len(values)]))
+		}
+		result, err := mask.MaskContext(model.NewDictionary().With("elements", elements), "elements")
+		assert.Nil(t, err)
+		sorted := []model.Entry{}
+		for _, element := range result.Get("elements").([]model.Dictionary) {
+			sorted = append(sorted, element.Get("v"))
+		}
+		assert.Equal(t, expected, sorted)
+	}
+}
+
+func TestMaskEngineShouldSampleInOrder(t *testing.T) {
+	mask, err := pipe.NewMaskFromConfig(model.PipeType{Sample: 3}, 42, map[string]model.Cache{})
+	assert.Nil(t, err)
+
+	order := map[model.Entry]int{"2020-01-01": 0, "2021-03-01": 1, "2019-01-01": 2, "2022-01-01": 3}
+	for i := 0; i < 10; i++ {
+		result, err := mask.MaskContext(transactions(), "transactions")
+		assert.Nil(t, err)
+		sampled := dates(result)
+		assert.Len(t, sampled, 3)
+		for j := 1; j < len(sampled); j++ {
+			assert.Less(t, order[sampled[j-1]], order[sampled[j]])
+		}
+	}
+}
+
+func TestMaskEngineShouldRepeatElements(t *testing.T) {
+	model.InjectMaskFactories([]model.MaskFactory{templatemask.Factory})
+	mask, err := pipe.NewMaskFromConfig(model.PipeType{
+		Repeat: 2,
+		Limit:  1,
+		Masking: []model.Masking{{
+			Selector: model.SelectorType{Jsonpath: "date"},
+			Mask:     model.MaskType{Template: "{{.date}}+"},
+		}},
+	}, 42, map[string]model.Cache{})
+	assert.Nil(t, err)
+
+	result, err := mask.MaskContext(transactions(), "transactions")
+	assert.Nil(t, err)
+	assert.Equal(t, []model.Entry{"2020-01-01+", "2020-01-01+"}, dates(result))
+}
+
+func TestMaskEngineShouldRejectInvalidTransformations(t *testing.T) {
+	for _, conf := range []model.PipeType{
+		{Limit: 1, Sample: 1},
+		{Limit: -1},
+		{Order: "desc"},
+		{SortBy: "date", Order: "random"},
+		{Filter: "{{ .date "},
+	} {
+		_, err := pipe.NewMaskFromConfig(conf, 42, map[string]model.Cache{})
+		assert.NotNil(t, err, conf)
+	}
+}
@@ -28,15 +28,17 @@
 
 // MaskEngine is a struct to mask the date
 type MaskEngine struct {
-	rand    *rand.Rand
+	rand *rand.Rand
+	model.RandState
 	DateMin time.Time
 	DateMax time.Time
 }
 
 // NewMask return a MaskEngine from 2 dates
 func NewMask(min, max time.Time, seed int64) MaskEngine {
+	source := model.NewRandSource(seed)
 	// nolint: gosec
-	return MaskEngine{rand.New(rand.NewSource(seed)), min, max}
+	return MaskEngine{rand.New(source), model.NewRandState(source), min, max}
 }
 
 // Mask choose a mask date randomly
@@ -33,6 +33,7 @@ type MaskEngine struct {
 	Min  time.Duration
 	Max  time.Duration
 	rand *rand.Rand
+	model.RandState
 }
 
 // NewMask create a MaskEngine with 2 ISO8601 duration strings
@@ -53,8 +54,9 @@ func NewMask(minString, maxString string, seed int64) (MaskEngine, error) {
 	} else {
 		durMax, err = duration.ParseDuration(maxString)
 	}
+	source := model.NewRandSource(seed)
 	// nolint: gosec
-	return MaskEngine{durMin, durMax, rand.New(rand.NewSource(seed))}, err
+	return MaskEngine{durMin, durMax, rand.New(source), model.NewRandState(source)}, err
 }
 
 // Mask masks a time value with a duration
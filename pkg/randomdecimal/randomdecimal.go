@@ -28,7 +28,8 @@
 
 // MaskEngine is a type to mask with a random float
 type MaskEngine struct {
-	rand      *rand.Rand
+	rand *rand.Rand
+	model.RandState
 	precision int
 	min       float64
 	max       float64
@@ -36,8 +37,9 @@ type MaskEngine struct {
 
 // NewMask create a MaskEngine with a seed
 func NewMask(min float64, max float64, precision int, seed int64) MaskEngine {
+	source := model.NewRandSource(seed)
 	// nolint: gosec
-	return MaskEngine{rand.New(rand.NewSource(seed)), precision, min, max}
+	return MaskEngine{rand.New(source), model.NewRandState(source), precision, min, max}
 }
 
 // Mask choose a mask int randomly within boundary
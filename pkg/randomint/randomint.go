@@ -28,14 +28,16 @@
 // MaskEngine is a list of number to mask randomly
 type MaskEngine struct {
 	rand *rand.Rand
-	min  int
-	max  int
+	model.RandState
+	min int
+	max int
 }
 
 // NewMask create a MaskEngine with a seed
 func NewMask(min int, max int, seed int64) MaskEngine {
+	source := model.NewRandSource(seed)
 	// nolint: gosec
-	return MaskEngine{rand.New(rand.NewSource(seed)), min, max}
+	return MaskEngine{rand.New(source), model.NewRandState(source), min, max}
 }
 
 // Mask choose a mask int randomly within boundary
@@ -28,13 +28,15 @@
 // MaskEngine is a list of masking value and a rand init to mask
 type MaskEngine struct {
 	rand *rand.Rand
+	model.RandState
 	list []model.Entry
 }
 
 // NewMaskSeeded create a MaskRandomList with a seed
 func NewMask(list []model.Entry, seed int64) MaskEngine {
+	source := model.NewRandSource(seed)
 	// nolint: gosec
-	return MaskEngine{rand.New(rand.NewSource(seed)), list}
+	return MaskEngine{rand.New(source), model.NewRandState(source), list}
 }
 
 // Mask choose a mask value randomly
@@ -30,7 +30,8 @@
 
 // MaskEngine is a list of masking value and a rand init to mask
 type MaskEngine struct {
-	rand     *rand.Rand
+	rand *rand.Rand
+	model.RandState
 	template *template.Template
 	cache    map[string][]model.Entry
 }
@@ -38,8 +39,9 @@ type MaskEngine struct {
 // NewMaskSeeded create a MaskRandomList with a seed
 func NewMask(templateSource string, seed int64) (MaskEngine, error) {
 	template, err := template.New("template-randomInUri").Parse(templateSource)
+	source := model.NewRandSource(seed)
 	// nolint: gosec
-	return MaskEngine{rand.New(rand.NewSource(seed)), template, map[string][]model.Entry{}}, err
+	return MaskEngine{rand.New(source), model.NewRandState(source), template, map[string][]model.Entry{}}, err
 }
 
 // Mask choose a mask value randomly
@@ -18,7 +18,6 @@
 package rangemask
 
 import (
-	"encoding/json"
 	"strconv"
 
 	"github.com/cgi-fr/pimo/pkg/model"
@@ -41,7 +40,7 @@ func (rm MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.Ent
 	if e == nil {
 		return e, nil
 	}
-	i, err := e.(json.Number).Int64()
+	i, err := model.ToInt64(e)
 	if err != nil {
 		return nil, err
 	}
@@ -42,3 +42,13 @@ func TestFactoryShouldCreateAMask(t *testing.T) {
 	assert.True(t, present, "should be true")
 	assert.Nil(t, err, "error should be nil")
 }
+
+func TestMaskingShouldAcceptIntegersAndStrings(t *testing.T) {
+	rangeMask := NewMask(10)
+	result, err := rangeMask.Mask(25)
+	assert.Nil(t, err)
+	assert.Equal(t, "[20;29]", result)
+	result, err = rangeMask.Mask("25")
+	assert.Nil(t, err)
+	assert.Equal(t, "[20;29]", result)
+}
@@ -18,6 +18,7 @@
 package regex
 
 import (
+	"encoding/json"
 	"hash/fnv"
 	"math/rand"
 
@@ -28,19 +29,46 @@
 
 // MaskEngine is a value that mask thanks to a regular expression
 type MaskEngine struct {
+	exp   string
+	state *generatorState
+}
+
+// generatorState is the generator, its seed and the number of values generated since seeded
+type generatorState struct {
 	generator regen.Generator
+	Seed      int64  `json:"seed"`
+	Generated uint64 `json:"generated"`
 }
 
+// fixedSource is used to seed the generator, which draws a single value from its source
+type fixedSource int64
+
+func (s fixedSource) Int63() int64 { return int64(s) }
+
+func (s fixedSource) Seed(int64) {}
+
 // NewMask return a RegexMask from a regexp
 func NewMask(exp string, seed int64) (MaskEngine, error) {
-	generator, err := regen.NewGenerator(exp, &regen.GeneratorArgs{RngSource: rand.NewSource(seed)})
-	return MaskEngine{generator}, err
+	mask := MaskEngine{exp, &generatorState{}}
+	// nolint: gosec
+	err := mask.seed(rand.NewSource(seed).Int63())
+	return mask, err
+}
+
+func (rm MaskEngine) seed(seed int64) error {
+	generator, err := regen.NewGenerator(rm.exp, &regen.GeneratorArgs{RngSource: fixedSource(seed)})
+	if err != nil {
+		return err
+	}
+	*rm.state = generatorState{generator, seed, 0}
+	return nil
 }
 
 // Mask returns a string thanks to a regular expression
 func (rm MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.Entry, error) {
 	log.Info().Msg("Mask regex")
-	out := rm.generator.Generate()
+	out := rm.state.generator.Generate()
+	rm.state.Generated++
 	return out, nil
 }
 
@@ -60,3 +88,30 @@ func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (mod
 	}
 	return nil, false, nil
 }
+
+// State returns the position of the random generator
+func (rm MaskEngine) State() (json.RawMessage, error) {
+	return json.Marshal(rm.state)
+}
+
+// Restore moves the random generator to a saved position
+func (rm MaskEngine) Restore(state json.RawMessage) error {
+	var saved generatorState
+	if err := json.Unmarshal(state, &saved); err != nil {
+		return err
+	}
+	if err := rm.seed(saved.Seed); err != nil {
+		return err
+	}
+	for rm.state.Generated < saved.Generated {
+		rm.state.generator.Generate()
+		rm.state.Generated++
+	}
+	return nil
+}
+
+// Seed resets the random generator with a new seed
+func (rm MaskEngine) Seed(seed int64) {
+	// the expression has already been parsed successfully by NewMask
+	_ = rm.seed(seed)
+}
@@ -55,3 +55,42 @@ func TestFactoryShouldNotCreateAMaskFromAnEmptyConfig(t *testing.T) {
 	assert.False(t, present, "should be false")
 	assert.Nil(t, err, "error should be nil")
 }
+
+func TestRestoreShouldReplayTheSequence(t *testing.T) {
+	regmask, err := NewMask("[A-Z]{8}", 0)
+	assert.Nil(t, err)
+	_, _ = regmask.Mask(nil)
+	state, err := regmask.State()
+	assert.Nil(t, err)
+
+	expected := []model.Entry{}
+	for i := 0; i < 5; i++ {
+		value, _ := regmask.Mask(nil)
+		expected = append(expected, value)
+	}
+
+	assert.Nil(t, regmask.Restore(state))
+	actual := []model.Entry{}
+	for i := 0; i < 5; i++ {
+		value, _ := regmask.Mask(nil)
+		actual = append(actual, value)
+	}
+	assert.Equal(t, expected, actual)
+
+	restored, err := NewMask("[A-Z]{8}", 1)
+	assert.Nil(t, err)
+	assert.Nil(t, restored.Restore(state))
+	value, _ := restored.Mask(nil)
+	assert.Equal(t, expected[0], value)
+}
+
+func TestSeedShouldRestartTheSequence(t *testing.T) {
+	regmask, err := NewMask("[A-Z]{8}", 0)
+	assert.Nil(t, err)
+
+	regmask.Seed(42)
+	first, _ := regmask.Mask(nil)
+	regmask.Seed(42)
+	second, _ := regmask.Mask(nil)
+	assert.Equal(t, first, second)
+}
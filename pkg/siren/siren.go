@@ -0,0 +1,129 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package siren
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+
+	"github.com/cgi-fr/pimo/pkg/luhn"
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// nolint: gochecknoglobals
+var checksum = luhn.NewMask([]byte("0123456789"))
+
+// MaskEngine is a mask to generate SIREN (9 digits) or SIRET (14 digits) with a valid Luhn check digit
+type MaskEngine struct {
+	rand *rand.Rand
+	model.RandState
+	siret      bool
+	sirenField string
+}
+
+// NewMask create a MaskEngine generating SIREN, or SIRET if siret is true.
+// A SIRET starts with the SIREN of the field sirenField if given.
+func NewMask(siret bool, sirenField string, seed int64) MaskEngine {
+	source := model.NewRandSource(seed)
+	// nolint: gosec
+	return MaskEngine{rand.New(source), model.NewRandState(source), siret, sirenField}
+}
+
+// Mask returns a new SIREN or SIRET
+func (me MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.Entry, error) {
+	if me.siret {
+		log.Info().Msg("Mask siret")
+	} else {
+		log.Info().Msg("Mask siren")
+	}
+
+	siren := ""
+	if me.sirenField != "" && len(context) > 0 {
+		if value, ok := context[0].GetValue(me.sirenField); ok && value != nil {
+			siren = fmt.Sprint(value)
+			if !Valid(siren) || len(siren) != 9 {
+				return nil, fmt.Errorf("'%s' is not a valid SIREN", siren)
+			}
+		}
+	}
+	if siren == "" {
+		var err error
+		if siren, err = me.number(8); err != nil {
+			return nil, err
+		}
+	}
+	if !me.siret {
+		return siren, nil
+	}
+
+	nic := me.randomDigits(4)
+	return checksum.Mask(siren + nic)
+}
+
+// number returns random digits followed by a Luhn check digit
+func (me MaskEngine) number(length int) (string, error) {
+	result, err := checksum.Mask(me.randomDigits(length))
+	if err != nil {
+		return "", err
+	}
+	return result.(string), nil
+}
+
+func (me MaskEngine) randomDigits(length int) string {
+	var sb strings.Builder
+	for i := 0; i < length; i++ {
+		sb.WriteByte(byte('0' + me.rand.Intn(10)))
+	}
+	return sb.String()
+}
+
+// Valid returns true if the number has a valid Luhn check digit
+func Valid(number string) bool {
+	if len(number) < 2 || strings.Trim(number, "0123456789") != "" {
+		return false
+	}
+	result, err := checksum.Mask(number[:len(number)-1])
+	return err == nil && result == number
+}
+
+// Factory create a SIREN mask from a yaml config
+func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (model.MaskEngine, bool, error) {
+	if conf.Mask.SIREN {
+		// set differents seeds for differents jsonpath
+		h := fnv.New64a()
+		h.Write([]byte(conf.Selector.Jsonpath))
+		seed += int64(h.Sum64())
+		return NewMask(false, "", seed), true, nil
+	}
+	return nil, false, nil
+}
+
+// SIRETFactory create a SIRET mask from a yaml config
+func SIRETFactory(conf model.Masking, seed int64, caches map[string]model.Cache) (model.MaskEngine, bool, error) {
+	if conf.Mask.SIRET != nil {
+		// set differents seeds for differents jsonpath
+		h := fnv.New64a()
+		h.Write([]byte(conf.Selector.Jsonpath))
+		seed += int64(h.Sum64())
+		return NewMask(true, conf.Mask.SIRET.SIRENField, seed), true, nil
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,59 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+package siren
+
+import (
+	"testing"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValid(t *testing.T) {
+	assert.True(t, Valid("732829320"))
+	assert.True(t, Valid("73282932000074"))
+	assert.False(t, Valid("732829321"))
+	assert.False(t, Valid("7328A9320"))
+}
+
+func TestMaskingShouldGenerateValidSIREN(t *testing.T) {
+	mask := NewMask(false, "", 42)
+	for i := 0; i < 100; i++ {
+		result, err := mask.Mask("732829320")
+		assert.Nil(t, err)
+		assert.Len(t, result, 9)
+		assert.True(t, Valid(result.(string)))
+	}
+}
+
+func TestMaskingShouldGenerateSIRETFromSIREN(t *testing.T) {
+	mask := NewMask(true, "siren", 42)
+
+	result, err := mask.Mask("73282932000074", model.NewDictionary().With("siren", "732829320"))
+	assert.Nil(t, err)
+	assert.Regexp(t, `^732829320[0-9]{5}$`, result)
+	assert.True(t, Valid(result.(string)))
+
+	_, err = mask.Mask("73282932000074", model.NewDictionary().With("siren", "123"))
+	assert.NotNil(t, err)
+
+	result, err = mask.Mask("73282932000074", model.NewDictionary())
+	assert.Nil(t, err)
+	assert.Len(t, result, 14)
+	assert.True(t, Valid(result.(string)))
+	assert.True(t, Valid(result.(string)[:9]))
+}
@@ -8,17 +8,21 @@
 )
 
 type ExecutionStats interface {
-	GetIgnoredPathsCount() int  // counter for path not found in data
-	GetIgnoredLinesCount() int  // counter for line skipped (flag --skip-line-on-error)
-	GetIgnoredFieldsCount() int // counter for field skipped (flag --skip-field-on-error)
+	GetIgnoredPathsCount() int        // counter for path not found in data
+	GetIgnoredLinesCount() int        // counter for line skipped (flag --skip-line-on-error)
+	GetIgnoredFieldsCount() int       // counter for field skipped (flag --skip-field-on-error)
+	GetInvalidLinesCount() int        // counter for line not valid against the output schema (flag --validate-output)
+	GetEvictedCacheEntriesCount() int // counter for cache entries evicted (maxEntries or ttl of caches)
 
 	ToJSON() []byte
 }
 
 type stats struct {
-	IgnoredPathsCounter  int `json:"ignoredPaths"`
-	IgnoredLinesCounter  int `json:"skippedLines"`
-	IgnoredFieldsCounter int `json:"skippedFields"`
+	IgnoredPathsCounter        int `json:"ignoredPaths"`
+	IgnoredLinesCounter        int `json:"skippedLines"`
+	IgnoredFieldsCounter       int `json:"skippedFields"`
+	InvalidLinesCounter        int `json:"invalidLines"`
+	EvictedCacheEntriesCounter int `json:"evictedCacheEntries"`
 }
 
 // Reset all statistics to zero
@@ -56,6 +60,14 @@ func (s *stats) GetIgnoredFieldsCount() int {
 	return s.IgnoredFieldsCounter
 }
 
+func (s *stats) GetInvalidLinesCount() int {
+	return s.InvalidLinesCounter
+}
+
+func (s *stats) GetEvictedCacheEntriesCount() int {
+	return s.EvictedCacheEntriesCounter
+}
+
 func IncIgnoredPathsCount() {
 	stats := getStats()
 	stats.IgnoredPathsCounter++
@@ -71,6 +83,16 @@ func IncIgnoredFieldsCount() {
 	stats.IgnoredFieldsCounter++
 }
 
+func IncInvalidLinesCount() {
+	stats := getStats()
+	stats.InvalidLinesCounter++
+}
+
+func IncEvictedCacheEntriesCount() {
+	stats := getStats()
+	stats.EvictedCacheEntriesCounter++
+}
+
 // Compute current statistics and give a snapshot
 func getStats() *stats {
 	value, exists := over.MDC().Get("stats")
@@ -0,0 +1,299 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package template
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	b64 "encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/capitalone/fpe/ff1"
+	"github.com/cgi-fr/pimo/pkg/maskingdata"
+)
+
+const (
+	alpha   = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	numeric = "0123456789"
+)
+
+// randFuncs returns the random functions, they replace the unseeded sprig functions with the same names
+func (o options) randFuncs() map[string]interface{} {
+	return map[string]interface{}{
+		"randInt": func(min, max interface{}) (int, error) {
+			minInt, maxInt, err := toIntRange(min, max)
+			if err != nil {
+				return 0, err
+			}
+			return minInt + o.rand.Intn(maxInt-minInt), nil
+		},
+		"randFloat": func(min, max interface{}) (float64, error) {
+			minFloat, err := toFloat(min)
+			if err != nil {
+				return 0, err
+			}
+			maxFloat, err := toFloat(max)
+			if err != nil {
+				return 0, err
+			}
+			return minFloat + o.rand.Float64()*(maxFloat-minFloat), nil
+		},
+		"randChoice": func(items ...interface{}) (interface{}, error) {
+			if len(items) == 1 {
+				if v := reflect.ValueOf(items[0]); v.Kind() == reflect.Slice {
+					items = make([]interface{}, v.Len())
+					for i := range items {
+						items[i] = v.Index(i).Interface()
+					}
+				}
+			}
+			if len(items) == 0 {
+				return nil, fmt.Errorf("randChoice needs at least one item")
+			}
+			return items[o.rand.Intn(len(items))], nil
+		},
+		"randAlpha":    func(count int) string { return o.randString(alpha, count) },
+		"randNumeric":  func(count int) string { return o.randString(numeric, count) },
+		"randAlphaNum": func(count int) string { return o.randString(alpha+numeric, count) },
+		"randAscii": func(count int) string {
+			var ascii strings.Builder
+			for c := ' '; c <= '~'; c++ {
+				ascii.WriteRune(c)
+			}
+			return o.randString(ascii.String(), count)
+		},
+		"fakeName": o.fakeName,
+	}
+}
+
+// maskFuncs returns the functions reusing the algorithms of masks
+func (o options) maskFuncs() map[string]interface{} {
+	return map[string]interface{}{
+		"cacheGet": func(cache string, key interface{}) (interface{}, error) {
+			if o.caches == nil {
+				return nil, fmt.Errorf("cache '%s' is not available in this template", cache)
+			}
+			return o.caches(cache, key)
+		},
+		"sha256": func(value interface{}) string {
+			sum := sha256.Sum256([]byte(toString(value)))
+			return hex.EncodeToString(sum[:])
+		},
+		"hmac": func(key string, value interface{}) string {
+			mac := hmac.New(sha256.New, []byte(key))
+			mac.Write([]byte(toString(value)))
+			return hex.EncodeToString(mac.Sum(nil))
+		},
+		"luhn": Luhn,
+		"ff1Encrypt": func(key string, tweak interface{}, radix interface{}, value interface{}) (string, error) {
+			return ff1Cipher(key, tweak, radix, value, false)
+		},
+		"ff1Decrypt": func(key string, tweak interface{}, radix interface{}, value interface{}) (string, error) {
+			return ff1Cipher(key, tweak, radix, value, true)
+		},
+		"dateParse":  DateParse,
+		"dateFormat": DateFormat,
+		"jsonpath":   JSONPath,
+	}
+}
+
+func (o options) randString(letters string, count int) string {
+	result := make([]byte, count)
+	for i := range result {
+		result[i] = letters[o.rand.Intn(len(letters))]
+	}
+	return string(result)
+}
+
+// fakeName returns a first name and a surname from the datasets of the locale, the gender (M or F) is optional
+func (o options) fakeName(locale string, gender ...string) (string, error) {
+	locale = strings.ToUpper(locale)
+	suffix := ""
+	if len(gender) > 0 {
+		suffix = strings.ToUpper(gender[0])
+	}
+	name, err := o.pickData("name" + locale + suffix)
+	if err != nil {
+		return "", err
+	}
+	surname, err := o.pickData("surname" + locale)
+	if err != nil {
+		return "", err
+	}
+	return name + " " + surname, nil
+}
+
+// pickData picks a value from a built-in dataset, using the frequencies if the dataset has some
+func (o options) pickData(dataset string) (string, error) {
+	if weighted, ok := maskingdata.WeightedData[dataset]; ok && len(weighted) > 0 {
+		total := int64(0)
+		for _, w := range weighted {
+			total += int64(w.Weight)
+		}
+		pick := o.rand.Int63n(total)
+		for _, w := range weighted {
+			pick -= int64(w.Weight)
+			if pick < 0 {
+				return w.Value, nil
+			}
+		}
+	}
+	if values, ok := maskingdata.MapData[dataset]; ok && len(values) > 0 {
+		return values[o.rand.Intn(len(values))], nil
+	}
+	return "", fmt.Errorf("dataset '%s' not found", dataset)
+}
+
+// Luhn returns the value followed by its Luhn check digit
+func Luhn(value interface{}) (string, error) {
+	input := toString(value)
+	factor := 2
+	sum := 0
+	for i := len(input) - 1; i >= 0; i-- {
+		digit := int(input[i] - '0')
+		if digit < 0 || digit > 9 {
+			return "", fmt.Errorf("luhn needs a number, got '%s'", input)
+		}
+		addend := factor * digit
+		sum += addend/10 + addend%10
+		factor = 3 - factor
+	}
+	return input + strconv.Itoa((10-sum%10)%10), nil
+}
+
+func ff1Cipher(key string, tweak interface{}, radix interface{}, value interface{}, decrypt bool) (string, error) {
+	decodedKey, err := b64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return "", err
+	}
+	radixInt, err := toInt(radix)
+	if err != nil {
+		return "", err
+	}
+	tweakString := toString(tweak)
+	cipher, err := ff1.NewCipher(radixInt, len(tweakString), decodedKey, []byte(tweakString))
+	if err != nil {
+		return "", err
+	}
+	if decrypt {
+		return cipher.Decrypt(toString(value))
+	}
+	return cipher.Encrypt(toString(value))
+}
+
+// DateParse parses a date with a Go layout like the dateParser mask, RFC3339 is used if the layout is empty
+func DateParse(layout string, value interface{}) (time.Time, error) {
+	if t, ok := value.(time.Time); ok {
+		return t, nil
+	}
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	return time.Parse(layout, toString(value))
+}
+
+// DateFormat formats a date (or a RFC3339 string) with a Go layout
+func DateFormat(layout string, value interface{}) (string, error) {
+	t, err := DateParse("", value)
+	if err != nil {
+		return "", err
+	}
+	return t.Format(layout), nil
+}
+
+// JSONPath reads the value at the path in the context, values of arrays are read in each element
+func JSONPath(path string, context interface{}) interface{} {
+	if context == nil {
+		return nil
+	}
+	current := reflect.ValueOf(context)
+	if current.Kind() == reflect.Slice {
+		result := make([]interface{}, current.Len())
+		for i := range result {
+			result[i] = JSONPath(path, current.Index(i).Interface())
+		}
+		return result
+	}
+
+	key, sub := path, ""
+	if i := strings.Index(path, "."); i >= 0 {
+		key, sub = path[:i], path[i+1:]
+	}
+
+	var value interface{}
+	switch {
+	case current.Kind() == reflect.Map:
+		v := current.MapIndex(reflect.ValueOf(key))
+		if !v.IsValid() {
+			return nil
+		}
+		value = v.Interface()
+	default:
+		getter, ok := context.(interface {
+			GetValue(string) (interface{}, bool)
+		})
+		if !ok {
+			return nil
+		}
+		value, _ = getter.GetValue(key)
+	}
+	if sub == "" {
+		return value
+	}
+	return JSONPath(sub, value)
+}
+
+func toString(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func toInt(value interface{}) (int, error) {
+	f, err := toFloat(value)
+	return int(f), err
+}
+
+func toFloat(value interface{}) (float64, error) {
+	f, err := strconv.ParseFloat(toString(value), 64)
+	if err != nil {
+		return 0, fmt.Errorf("'%v' is not a number", value)
+	}
+	return f, nil
+}
+
+func toIntRange(min, max interface{}) (int, int, error) {
+	minInt, err := toInt(min)
+	if err != nil {
+		return 0, 0, err
+	}
+	maxInt, err := toInt(max)
+	if err != nil {
+		return 0, 0, err
+	}
+	if maxInt <= minInt {
+		return 0, 0, fmt.Errorf("max %d must be greater than min %d", maxInt, minInt)
+	}
+	return minInt, maxInt, nil
+}
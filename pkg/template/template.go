@@ -1,8 +1,10 @@
 package template
 
 import (
+	"math/rand"
 	"strings"
 	"text/template"
+	"time"
 	"unicode"
 
 	"github.com/Masterminds/sprig/v3"
@@ -16,21 +18,67 @@ type Engine struct {
 	*template.Template
 }
 
-// rmAcc removes accents from string
+// Option configures the functions available in a template
+type Option func(*options)
+
+type options struct {
+	rand   *rand.Rand
+	caches CacheReader
+}
+
+// CacheReader reads the value of a key in a cache of the masking configuration
+type CacheReader func(cache string, key interface{}) (interface{}, error)
+
+// WithRand uses the source for the random functions, to get reproducible results
+func WithRand(source rand.Source) Option {
+	return func(o *options) {
+		// nolint: gosec
+		o.rand = rand.New(source)
+	}
+}
+
+// WithCacheReader gives access to the caches with the cacheGet function
+func WithCacheReader(reader CacheReader) Option {
+	return func(o *options) {
+		o.caches = reader
+	}
+}
+
+// NoAccent removes accents from string
 // Function derived from: http://blog.golang.org/normalization
-func rmAcc(s string) string {
+func NoAccent(s string) string {
 	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
 	result, _, _ := transform.String(t, s)
 	return result
 }
 
-// NewEngine create a template Engine
-func NewEngine(text string) (*Engine, error) {
+// FuncMap returns the functions available in templates, sprig functions excluded
+func FuncMap(opts ...Option) template.FuncMap {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.rand == nil {
+		// nolint: gosec
+		o.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
 	funcMap := template.FuncMap{
 		"ToUpper":  strings.ToUpper,
 		"ToLower":  strings.ToLower,
-		"NoAccent": rmAcc,
+		"NoAccent": NoAccent,
+	}
+	for name, function := range o.randFuncs() {
+		funcMap[name] = function
 	}
-	temp, err := template.New("template").Funcs(sprig.TxtFuncMap()).Funcs(funcMap).Parse(text)
+	for name, function := range o.maskFuncs() {
+		funcMap[name] = function
+	}
+	return funcMap
+}
+
+// NewEngine create a template Engine
+func NewEngine(text string, opts ...Option) (*Engine, error) {
+	temp, err := template.New("template").Funcs(sprig.TxtFuncMap()).Funcs(FuncMap(opts...)).Parse(text)
 	return &Engine{temp}, err
 }
@@ -0,0 +1,127 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func execute(t *testing.T, text string, context interface{}, opts ...Option) string {
+	engine, err := NewEngine(text, opts...)
+	assert.Nil(t, err)
+	var output bytes.Buffer
+	assert.Nil(t, engine.Execute(&output, context))
+	return output.String()
+}
+
+func TestRandomFunctionsShouldBeSeeded(t *testing.T) {
+	text := `{{randInt 0 1000}} {{randFloat 1 2}} {{randChoice "a" "b" "c"}} {{randAlphaNum 8}} {{fakeName "DE"}}`
+
+	first := execute(t, text, nil, WithRand(rand.NewSource(42)))
+	second := execute(t, text, nil, WithRand(rand.NewSource(42)))
+	other := execute(t, text, nil, WithRand(rand.NewSource(43)))
+
+	assert.Equal(t, first, second)
+	assert.NotEqual(t, first, other)
+}
+
+func TestRandomFunctionsShouldRespectBounds(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		result := execute(t, `{{randInt 3 5}}-{{randChoice (list "x" "y")}}-{{randNumeric 4}}`, nil)
+		parts := strings.Split(result, "-")
+		assert.Contains(t, []string{"3", "4"}, parts[0])
+		assert.Contains(t, []string{"x", "y"}, parts[1])
+		assert.Len(t, parts[2], 4)
+	}
+
+	engine, _ := NewEngine(`{{randInt 5 5}}`)
+	assert.NotNil(t, engine.Execute(&bytes.Buffer{}, nil))
+}
+
+func TestFakeName(t *testing.T) {
+	result := execute(t, `{{fakeName "fr" "F"}}`, nil)
+	assert.Len(t, strings.Split(result, " "), 2)
+
+	engine, _ := NewEngine(`{{fakeName "XX"}}`)
+	assert.NotNil(t, engine.Execute(&bytes.Buffer{}, nil))
+}
+
+func TestHashFunctions(t *testing.T) {
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", execute(t, `{{sha256 "hello"}}`, nil))
+	assert.Equal(t, "9307b3b915efb5171ff14d8cb55fbcc798c6c0ef1456d66ded1a6aa723a58b7b", execute(t, `{{hmac "key" "hello"}}`, nil))
+}
+
+func TestLuhn(t *testing.T) {
+	assert.Equal(t, "79927398713", execute(t, `{{luhn .number}}`, map[string]interface{}{"number": json.Number("7992739871")}))
+
+	_, err := Luhn("12a")
+	assert.NotNil(t, err)
+}
+
+func TestFF1(t *testing.T) {
+	context := map[string]interface{}{"key": "70NZ2NWAqk9/A21vBPxqlA==", "id": "123456789"}
+	encrypted := execute(t, `{{ff1Encrypt .key "tweak" 10 .id}}`, context)
+	assert.NotEqual(t, "123456789", encrypted)
+	assert.Len(t, encrypted, 9)
+
+	context["id"] = encrypted
+	assert.Equal(t, "123456789", execute(t, `{{ff1Decrypt .key "tweak" 10 .id}}`, context))
+}
+
+func TestDateFunctions(t *testing.T) {
+	context := map[string]interface{}{"birth": "12/03/1985", "date": "2020-01-02T03:04:05Z"}
+	assert.Equal(t, "1985-03-12", execute(t, `{{dateParse "02/01/2006" .birth | dateFormat "2006-01-02"}}`, context))
+	assert.Equal(t, "02/01/2020", execute(t, `{{dateFormat "02/01/2006" .date}}`, context))
+	assert.Equal(t, "2020", execute(t, `{{(dateParse "" .date).Year}}`, context))
+}
+
+func TestJSONPath(t *testing.T) {
+	context := map[string]interface{}{
+		"person":   map[string]interface{}{"address": map[string]interface{}{"city": "Nantes"}},
+		"contacts": []interface{}{map[string]interface{}{"email": "a@mail.com"}, map[string]interface{}{"email": "b@mail.com"}},
+	}
+	assert.Equal(t, "Nantes", execute(t, `{{jsonpath "person.address.city" .}}`, context))
+	assert.Equal(t, "[a@mail.com b@mail.com]", execute(t, `{{jsonpath "contacts.email" .}}`, context))
+	assert.Equal(t, "<no value>", execute(t, `{{jsonpath "person.name" .}}`, context))
+}
+
+func TestCacheGet(t *testing.T) {
+	caches := map[string]map[interface{}]interface{}{"ids": {"1": "A"}}
+	reader := func(cache string, key interface{}) (interface{}, error) {
+		values, ok := caches[cache]
+		if !ok {
+			return nil, fmt.Errorf("Cache '%s' not found", cache)
+		}
+		return values[key], nil
+	}
+
+	assert.Equal(t, "A", execute(t, `{{cacheGet "ids" "1"}}`, nil, WithCacheReader(reader)))
+
+	engine, _ := NewEngine(`{{cacheGet "unknown" "1"}}`, WithCacheReader(reader))
+	assert.NotNil(t, engine.Execute(&bytes.Buffer{}, nil))
+
+	engine, _ = NewEngine(`{{cacheGet "ids" "1"}}`)
+	assert.NotNil(t, engine.Execute(&bytes.Buffer{}, nil))
+}
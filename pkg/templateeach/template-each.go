@@ -19,45 +19,29 @@
 
 import (
 	"bytes"
-	"strings"
-	"text/template"
-	"unicode"
+	"hash/fnv"
 
-	sprig "github.com/Masterminds/sprig/v3"
 	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/cgi-fr/pimo/pkg/template"
 	"github.com/rs/zerolog/log"
-	"golang.org/x/text/runes"
-	"golang.org/x/text/transform"
-	"golang.org/x/text/unicode/norm"
 )
 
 // MaskEngine is to mask a value thanks to a template
 type MaskEngine struct {
-	template  *template.Template
+	template *template.Engine
+	model.RandState
 	itemName  string
 	indexName string
 }
 
-// rmAcc removes accents from string
-// Function derived from: http://blog.golang.org/normalization
-func rmAcc(s string) string {
-	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
-	result, _, _ := transform.String(t, s)
-	return result
-}
-
-// NewMask create a MaskEngine
-func NewMask(text string, itemName string, indexName string) (MaskEngine, error) {
-	funcMap := template.FuncMap{
-		"ToUpper":  strings.ToUpper,
-		"ToLower":  strings.ToLower,
-		"NoAccent": rmAcc,
-	}
-	temp, err := template.New("template-each").Funcs(sprig.TxtFuncMap()).Funcs(funcMap).Parse(text)
+// NewMask create a MaskEngine, random functions of the template are seeded with the seed
+func NewMask(text string, itemName string, indexName string, seed int64, caches map[string]model.Cache) (MaskEngine, error) {
+	source := model.NewRandSource(seed)
+	temp, err := model.NewTemplateEngine(text, source, caches)
 	if len(itemName) == 0 {
 		itemName = "it"
 	}
-	return MaskEngine{temp, itemName, indexName}, err
+	return MaskEngine{temp, model.NewRandState(source), itemName, indexName}, err
 }
 
 // Mask masks a value with a template
@@ -104,7 +88,11 @@ func (tmpl MaskEngine) MaskContext(context model.Dictionary, key string, context
 // Factory create a mask from a yaml config
 func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (model.MaskContextEngine, bool, error) {
 	if len(conf.Mask.TemplateEach.Template) != 0 {
-		mask, err := NewMask(conf.Mask.TemplateEach.Template, conf.Mask.TemplateEach.Item, conf.Mask.TemplateEach.Index)
+		// set differents seeds for differents jsonpath
+		h := fnv.New64a()
+		h.Write([]byte(conf.Selector.Jsonpath))
+		seed += int64(h.Sum64())
+		mask, err := NewMask(conf.Mask.TemplateEach.Template, conf.Mask.TemplateEach.Item, conf.Mask.TemplateEach.Index, seed, caches)
 		if err != nil {
 			return nil, false, err
 		}
@@ -19,6 +19,7 @@
 
 import (
 	"bytes"
+	"hash/fnv"
 
 	"github.com/cgi-fr/pimo/pkg/model"
 	"github.com/cgi-fr/pimo/pkg/template"
@@ -28,12 +29,14 @@
 // MaskEngine is to mask a value thanks to a template
 type MaskEngine struct {
 	template *template.Engine
+	model.RandState
 }
 
-// NewMask create a MaskEngine
-func NewMask(text string) (MaskEngine, error) {
-	temp, err := template.NewEngine(text)
-	return MaskEngine{temp}, err
+// NewMask create a MaskEngine, random functions of the template are seeded with the seed
+func NewMask(text string, seed int64, caches map[string]model.Cache) (MaskEngine, error) {
+	source := model.NewRandSource(seed)
+	temp, err := model.NewTemplateEngine(text, source, caches)
+	return MaskEngine{temp, model.NewRandState(source)}, err
 }
 
 // Mask masks a value with a template
@@ -47,7 +50,11 @@ func (tmpl MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.E
 // Factory create a mask from a yaml config
 func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (model.MaskEngine, bool, error) {
 	if len(conf.Mask.Template) != 0 {
-		mask, err := NewMask(conf.Mask.Template)
+		// set differents seeds for differents jsonpath
+		h := fnv.New64a()
+		h.Write([]byte(conf.Selector.Jsonpath))
+		seed += int64(h.Sum64())
+		mask, err := NewMask(conf.Mask.Template, seed, caches)
 		if err != nil {
 			return nil, false, err
 		}
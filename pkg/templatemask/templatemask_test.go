@@ -26,7 +26,7 @@
 
 func TestMaskingShouldReplaceSensitiveValueByTemplate(t *testing.T) {
 	template := "{{.name}}.{{.surname}}@gmail.com"
-	tempMask, err := NewMask(template)
+	tempMask, err := NewMask(template, 0, nil)
 	if err != nil {
 		assert.Fail(t, err.Error())
 	}
@@ -40,7 +40,7 @@ func TestMaskingShouldReplaceSensitiveValueByTemplate(t *testing.T) {
 
 func TestMaskingShouldReplaceSensitiveValueByTemplateInNested(t *testing.T) {
 	template := "{{.customer.identity.name}}.{{.customer.identity.surname}}@gmail.com"
-	tempMask, err := NewMask(template)
+	tempMask, err := NewMask(template, 0, nil)
 	if err != nil {
 		assert.Fail(t, err.Error())
 	}
@@ -63,7 +63,7 @@ func TestFactoryShouldCreateAMask(t *testing.T) {
 	maskingConfig := model.Masking{Selector: model.SelectorType{Jsonpath: "mail"}, Mask: model.MaskType{Template: "{{.name}}.{{.surname}}@gmail.com"}}
 	config, present, err := Factory(maskingConfig, 0, nil)
 	assert.Nil(t, err, "error should be nil")
-	maskingEngine, _ := NewMask("{{.name}}.{{.surname}}@gmail.com")
+	maskingEngine, _ := NewMask("{{.name}}.{{.surname}}@gmail.com", 0, nil)
 	assert.IsType(t, maskingEngine, config, "should be equal")
 	assert.True(t, present, "should be true")
 	assert.Nil(t, err, "error should be nil")
@@ -92,7 +92,7 @@ func TestFactoryShouldReturnAnErrorInWrongConfig(t *testing.T) {
 
 func TestMaskingTemplateShouldFormat(t *testing.T) {
 	template := `{{"hello!" | upper | repeat 2}}`
-	tempMask, err := NewMask(template)
+	tempMask, err := NewMask(template, 0, nil)
 	if err != nil {
 		assert.Fail(t, err.Error())
 	}
@@ -121,7 +121,7 @@ func TestRFactoryShouldCreateANestedContextMask(t *testing.T) {
 
 func TestMaskingTemplateShouldIterOverContextArray(t *testing.T) {
 	template := `{{- range $index, $rel := .REL_PERMIS -}}{{.ID_PERMIS}}{{- end -}}`
-	tempMask, err := NewMask(template)
+	tempMask, err := NewMask(template, 0, nil)
 	if err != nil {
 		assert.Fail(t, err.Error())
 	}
@@ -135,3 +135,28 @@ func TestMaskingTemplateShouldIterOverContextArray(t *testing.T) {
 	waited := "1"
 	assert.Equal(t, waited, result, "Should create the right field")
 }
+
+func TestMaskingTemplateShouldBeSeededAndReadCaches(t *testing.T) {
+	cache := model.NewMemCache()
+	cache.Put("1", "Alice")
+	caches := map[string]model.Cache{"names": cache}
+	template := `{{cacheGet "names" .id}}-{{randInt 0 1000000}}`
+
+	data := model.NewDictionary().With("id", "1")
+	first, _ := NewMask(template, 42, caches)
+	second, _ := NewMask(template, 42, caches)
+
+	result, err := first.Mask("anything", data)
+	assert.Nil(t, err)
+	assert.Regexp(t, "^Alice-[0-9]+$", result)
+	expected, err := second.Mask("anything", data)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, result)
+
+	state, err := first.State()
+	assert.Nil(t, err)
+	next, _ := first.Mask("anything", data)
+	assert.Nil(t, first.Restore(state))
+	replayed, _ := first.Mask("anything", data)
+	assert.Equal(t, next, replayed)
+}
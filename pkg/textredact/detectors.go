@@ -0,0 +1,72 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package textredact
+
+import (
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/cgi-fr/pimo/pkg/iban"
+	"github.com/cgi-fr/pimo/pkg/luhn"
+)
+
+// detector finds sensitive values in a text, valid filters false positives of the regular expression
+type detector struct {
+	regex *regexp.Regexp
+	valid func(string) bool
+	label string
+}
+
+// nolint: gochecknoglobals
+var checksum = luhn.NewMask([]byte("0123456789"))
+
+// nolint: gochecknoglobals
+var builtins = map[string]detector{
+	"email": {
+		regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`),
+		nil,
+		"[EMAIL]",
+	},
+	"phone": {
+		regexp.MustCompile(`(?:\+\d{1,3}[ .-]?|\b00\d{1,3}[ .-]?|\b0)[1-9](?:[ .-]?\d{2}){4}\b|\+1[ .-]?\(?\d{3}\)?[ .-]?\d{3}[ .-]?\d{4}\b`),
+		nil,
+		"[PHONE]",
+	},
+	"iban": {
+		regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]){11,30}\b`),
+		func(s string) bool { return iban.Valid(strings.ReplaceAll(s, " ", "")) },
+		"[IBAN]",
+	},
+	"creditCard": {
+		regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		validLuhn,
+		"[CARD]",
+	},
+	"ip": {
+		regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b|(?:[0-9A-Fa-f]{0,4}:){2,7}[0-9A-Fa-f]{1,4}`),
+		func(s string) bool { return net.ParseIP(s) != nil },
+		"[IP]",
+	},
+}
+
+func validLuhn(s string) bool {
+	number := strings.NewReplacer(" ", "", "-", "").Replace(s)
+	result, err := checksum.Mask(number[:len(number)-1])
+	return err == nil && result == number
+}
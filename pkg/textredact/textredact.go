@@ -0,0 +1,145 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package textredact
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultLabel replaces values found by a regular expression without mask
+const DefaultLabel = "[REDACTED]"
+
+// redactor is a detector with the mask producing replacement values
+type redactor struct {
+	detector
+	mask model.MaskEngine
+}
+
+// MaskEngine is a mask to replace sensitive values found in a text, the rest of the text is kept
+type MaskEngine struct {
+	redactors []redactor
+}
+
+// Mask returns the text with sensitive values replaced
+func (me MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.Entry, error) {
+	text, ok := e.(string)
+	if !ok {
+		log.Warn().Msg("Mask textRedact - ignored non string value")
+		return e, nil
+	}
+
+	log.Info().Msg("Mask textRedact")
+
+	type match struct {
+		start, end int
+		redactor   redactor
+	}
+
+	// find matches of every detector in the original text, the first detector wins on overlapping matches
+	matches := []match{}
+	for _, r := range me.redactors {
+	search:
+		for _, location := range r.regex.FindAllStringIndex(text, -1) {
+			if r.valid != nil && !r.valid(text[location[0]:location[1]]) {
+				continue
+			}
+			for _, m := range matches {
+				if location[0] < m.end && m.start < location[1] {
+					continue search
+				}
+			}
+			matches = append(matches, match{location[0], location[1], r})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+
+	var sb strings.Builder
+	position := 0
+	for _, m := range matches {
+		sb.WriteString(text[position:m.start])
+		if m.redactor.mask == nil {
+			sb.WriteString(m.redactor.label)
+		} else {
+			masked, err := m.redactor.mask.Mask(text[m.start:m.end], context...)
+			if err != nil {
+				return nil, err
+			}
+			sb.WriteString(fmt.Sprint(masked))
+		}
+		position = m.end
+	}
+	sb.WriteString(text[position:])
+
+	return sb.String(), nil
+}
+
+// Factory create a mask from a yaml config
+func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (model.MaskEngine, bool, error) {
+	if conf.Mask.TextRedact == nil {
+		return nil, false, nil
+	}
+	if len(conf.Mask.TextRedact.Detectors) == 0 {
+		return nil, true, fmt.Errorf("textRedact needs at least one detector")
+	}
+
+	redactors := []redactor{}
+	for i, definition := range conf.Mask.TextRedact.Detectors {
+		var d detector
+		switch {
+		case definition.Type != "" && definition.Regex != "":
+			return nil, true, fmt.Errorf("detector %d of textRedact accepts either a type or a regex", i)
+		case definition.Type != "":
+			builtin, ok := builtins[definition.Type]
+			if !ok {
+				return nil, true, fmt.Errorf("detector type '%s' is not supported", definition.Type)
+			}
+			d = builtin
+		case definition.Regex != "":
+			regex, err := regexp.Compile(definition.Regex)
+			if err != nil {
+				return nil, true, err
+			}
+			d = detector{regex, nil, DefaultLabel}
+		default:
+			return nil, true, fmt.Errorf("detector %d of textRedact needs a type or a regex", i)
+		}
+
+		var mask model.MaskEngine
+		if definition.Mask != nil {
+			// each sub-mask has its own selector, to get its own seed
+			subMasking := model.Masking{
+				Selector: model.SelectorType{Jsonpath: conf.Selector.Jsonpath + ".textRedact." + strconv.Itoa(i)},
+				Mask:     *definition.Mask,
+				Cache:    definition.Cache,
+			}
+			var err error
+			if mask, err = model.BuildMaskEngine(subMasking, seed, caches); err != nil {
+				return nil, true, err
+			}
+		}
+		redactors = append(redactors, redactor{d, mask})
+	}
+	return MaskEngine{redactors}, true, nil
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+package textredact
+
+import (
+	"testing"
+
+	"github.com/cgi-fr/pimo/pkg/constant"
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskingShouldRedactBuiltinDetectors(t *testing.T) {
+	conf := model.Masking{
+		Selector: model.SelectorType{Jsonpath: "comment"},
+		Mask: model.MaskType{TextRedact: &model.TextRedactType{Detectors: []model.DetectorType{
+			{Type: "email"}, {Type: "phone"}, {Type: "iban"}, {Type: "creditCard"}, {Type: "ip"},
+		}}},
+	}
+	mask, present, err := Factory(conf, 42, nil)
+	assert.True(t, present)
+	assert.Nil(t, err)
+
+	result, err := mask.Mask("Mail jean.dupont@example.com or call 06 12 34 56 78 / +33 6 12 34 56 78. " +
+		"IBAN FR76 3000 6000 0112 3456 7890 189, card 4111 1111 1111 1111, from 192.168.0.1 at 10:30:00. Ref 4111 1111 1111 1112.")
+	assert.Nil(t, err)
+	assert.Equal(t, "Mail [EMAIL] or call [PHONE] / [PHONE]. "+
+		"IBAN [IBAN], card [CARD], from [IP] at 10:30:00. Ref 4111 1111 1111 1112.", result)
+}
+
+func TestMaskingShouldUseSubMasksAndCaches(t *testing.T) {
+	model.InjectMaskFactories([]model.MaskFactory{constant.Factory})
+	caches := map[string]model.Cache{"names": model.NewMemCache()}
+	caches["names"].Put("Jean Dupont", "Paul Martin")
+
+	conf := model.Masking{
+		Selector: model.SelectorType{Jsonpath: "comment"},
+		Mask: model.MaskType{TextRedact: &model.TextRedactType{Detectors: []model.DetectorType{
+			{Regex: "Jean Dupont|Marie Curie", Mask: &model.MaskType{Constant: "Someone"}, Cache: "names"},
+			{Type: "email", Mask: &model.MaskType{Constant: "contact@example.com"}},
+			{Regex: "[0-9]{5}"},
+		}}},
+	}
+	mask, present, err := Factory(conf, 42, caches)
+	assert.True(t, present)
+	assert.Nil(t, err)
+
+	result, err := mask.Mask("Jean Dupont (jd@corp.com) met Marie Curie in 75005.")
+	assert.Nil(t, err)
+	assert.Equal(t, "Paul Martin (contact@example.com) met Someone in [REDACTED].", result)
+}
+
+func TestFactoryShouldRejectInvalidDetectors(t *testing.T) {
+	for _, detectors := range [][]model.DetectorType{
+		{},
+		{{Type: "unknown"}},
+		{{Regex: "("}},
+		{{Type: "email", Regex: ".*"}},
+		{{}},
+	} {
+		conf := model.Masking{Mask: model.MaskType{TextRedact: &model.TextRedactType{Detectors: detectors}}}
+		_, present, err := Factory(conf, 42, nil)
+		assert.True(t, present)
+		assert.NotNil(t, err)
+	}
+}
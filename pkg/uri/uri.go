@@ -20,17 +20,32 @@
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 
 	"github.com/cgi-fr/pimo/pkg/maskingdata"
 	"github.com/cgi-fr/pimo/pkg/model"
 )
 
+// directories are searched for pimo:// resources before built-in datasets
+var directories = []string{} // nolint: gochecknoglobals
+
+// RegisterDirectory adds a directory containing datasets available with the pimo:// scheme,
+// a file named <name>, <name>.txt or <name>.csv in this directory is read by pimo://<name>
+func RegisterDirectory(dir string) {
+	directories = append(directories, dir)
+}
+
+// ResetDirectories removes all registered dataset directories
+func ResetDirectories() {
+	directories = []string{}
+}
+
 func Read(uri string) ([]model.Entry, error) {
-	var result []model.Entry
 	u, err := url.Parse(uri)
 	if err != nil {
 		return nil, err
@@ -40,17 +55,8 @@ func Read(uri string) ([]model.Entry, error) {
 		if err != nil {
 			return nil, err
 		}
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			value := scanner.Text()
-			intValue, err := strconv.Atoi(value)
-			if err == nil {
-				result = append(result, intValue)
-			} else {
-				result = append(result, scanner.Text())
-			}
-		}
-		return result, nil
+		defer file.Close()
+		return readLines(file)
 	}
 	if u.Scheme == "http" || u.Scheme == "https" {
 		/* #nosec */
@@ -59,29 +65,58 @@ func Read(uri string) ([]model.Entry, error) {
 			return nil, err
 		}
 		defer rep.Body.Close()
-		scanner := bufio.NewScanner(rep.Body)
-		for scanner.Scan() {
-			value := scanner.Text()
-			intValue, err := strconv.Atoi(value)
-			if err == nil {
-				result = append(result, intValue)
-			} else {
-				result = append(result, scanner.Text())
-			}
-		}
-		return result, nil
+		return readLines(rep.Body)
 	}
 	if u.Scheme == "pimo" {
-		list, ok := maskingdata.MapData[u.Host]
-		if !ok {
-			return nil, fmt.Errorf("Not a Pimo inside file")
-		}
-		result := make([]model.Entry, len(list))
-		for i, v := range list {
-			result[i] = v
-		}
-		return result, nil
+		return readDataset(u)
 	}
 
 	return nil, fmt.Errorf(u.Scheme + " is not a valid scheme")
 }
+
+// readDataset reads a dataset from registered directories or built-in datasets,
+// the weighted query returns built-in datasets as CSV rows value,weight
+func readDataset(u *url.URL) ([]model.Entry, error) {
+	for _, dir := range directories {
+		for _, name := range []string{u.Host, u.Host + ".txt", u.Host + ".csv"} {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err != nil || info.IsDir() {
+				continue
+			}
+			file, err := os.Open(path)
+			if err != nil {
+				return nil, err
+			}
+			defer file.Close()
+			return readLines(file)
+		}
+	}
+
+	list, ok := maskingdata.MapData[u.Host]
+	if _, weighted := u.Query()["weighted"]; weighted {
+		list, ok = maskingdata.WeightedLines(u.Host)
+	}
+	if !ok {
+		return nil, fmt.Errorf("Not a Pimo inside file")
+	}
+	result := make([]model.Entry, len(list))
+	for i, v := range list {
+		result[i] = v
+	}
+	return result, nil
+}
+
+func readLines(reader io.Reader) ([]model.Entry, error) {
+	var result []model.Entry
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		value := scanner.Text()
+		intValue, err := strconv.Atoi(value)
+		if err == nil {
+			result = append(result, intValue)
+		} else {
+			result = append(result, value)
+		}
+	}
+	return result, scanner.Err()
+}
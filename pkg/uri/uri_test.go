@@ -18,6 +18,8 @@
 package uri
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/cgi-fr/pimo/pkg/maskingdata"
@@ -55,3 +57,44 @@ func TestUriReaderShouldCreateListFromInsideFiles(t *testing.T) {
 		assert.Equal(t, waitedList[i], nameList[i], "Should return the right list")
 	}
 }
+
+func TestUriReaderShouldReadLocaleDatasets(t *testing.T) {
+	for _, name := range []string{"nameDE", "nameESF", "surnameIT", "townBE", "postcodeTownUK", "streetDE", "companyES"} {
+		list, err := Read("pimo://" + name)
+		assert.Nil(t, err, name)
+		assert.NotEmpty(t, list, name)
+	}
+}
+
+func TestUriReaderShouldReadWeightedDatasets(t *testing.T) {
+	list, err := Read("pimo://surnameDE?weighted")
+	assert.Nil(t, err)
+	assert.Equal(t, "Müller,256", list[0])
+
+	list, err = Read("pimo://postcodeTownUK?weighted")
+	assert.Nil(t, err)
+	assert.Equal(t, "London SW1A 1AA,8982000", list[0])
+
+	list, err = Read("pimo://nameFRM?weighted")
+	assert.Nil(t, err)
+	assert.Equal(t, maskingdata.NameFRM[0]+",1", list[0])
+}
+
+func TestUriReaderShouldReadRegisteredDirectories(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "colors.txt"), []byte("red\ngreen\n12\n"), 0o600))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "nameFR.csv"), []byte("Jean,3\n"), 0o600))
+	RegisterDirectory(dir)
+	defer ResetDirectories()
+
+	list, err := Read("pimo://colors")
+	assert.Nil(t, err)
+	assert.Equal(t, []model.Entry{"red", "green", 12}, list)
+
+	list, err = Read("pimo://nameFR")
+	assert.Nil(t, err)
+	assert.Equal(t, []model.Entry{"Jean,3"}, list)
+
+	_, err = Read("pimo://unknown")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,202 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package urlmask
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/cgi-fr/pimo/pkg/ip"
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	lower  = "abcdefghijklmnopqrstuvwxyz"
+	upper  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digits = "0123456789"
+)
+
+// MaskEngine is a mask to replace parts of URL, the scheme and the shape of the URL are kept
+type MaskEngine struct {
+	rand *rand.Rand
+	model.RandState
+	host      bool
+	path      bool
+	query     []string
+	addresses model.MaskEngine
+}
+
+// NewMask create a MaskEngine, query is the list of parameters to mask ("*" for all parameters),
+// addresses is the mask of IP address hosts (nil to keep them)
+func NewMask(host, path bool, query []string, addresses model.MaskEngine, seed int64) MaskEngine {
+	source := model.NewRandSource(seed)
+	// nolint: gosec
+	return MaskEngine{rand.New(source), model.NewRandState(source), host, path, query, addresses}
+}
+
+// Mask returns the URL with a new host, path or query parameters
+func (me MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.Entry, error) {
+	if e == nil {
+		log.Warn().Msg("Mask url - ignored null value")
+		return e, nil
+	}
+
+	log.Info().Msg("Mask url")
+
+	u, err := url.Parse(fmt.Sprint(e))
+	if err != nil {
+		return nil, err
+	}
+
+	if me.host {
+		if hostname := u.Hostname(); hostname != "" {
+			host, err := me.maskHost(hostname)
+			if err != nil {
+				return nil, err
+			}
+			if port := u.Port(); port != "" {
+				host += ":" + port
+			}
+			u.Host = host
+		}
+		if u.User != nil {
+			if password, ok := u.User.Password(); ok {
+				u.User = url.UserPassword(me.shape(u.User.Username()), me.shape(password))
+			} else {
+				u.User = url.User(me.shape(u.User.Username()))
+			}
+		}
+	}
+
+	if me.path && u.Path != "" {
+		u.Path = me.shape(u.Path)
+		u.RawPath = ""
+	}
+
+	if len(me.query) > 0 && u.RawQuery != "" {
+		u.RawQuery = me.maskQuery(u.RawQuery)
+	}
+
+	return u.String(), nil
+}
+
+// maskHost replaces a host name, or an IP address if a mask of addresses is set
+func (me MaskEngine) maskHost(hostname string) (string, error) {
+	address := net.ParseIP(hostname)
+	if address == nil {
+		return me.maskHostname(hostname), nil
+	}
+	if me.addresses == nil {
+		return hostname, nil
+	}
+	masked, err := me.addresses.Mask(hostname)
+	if err != nil {
+		return "", err
+	}
+	if address.To4() == nil {
+		return "[" + fmt.Sprint(masked) + "]", nil
+	}
+	return fmt.Sprint(masked), nil
+}
+
+// maskHostname replaces every label of the host name, except the top level domain and "www"
+func (me MaskEngine) maskHostname(hostname string) string {
+	labels := strings.Split(hostname, ".")
+	for i, label := range labels {
+		if (i == len(labels)-1 && len(labels) > 1) || label == "www" {
+			continue
+		}
+		labels[i] = strings.ToLower(me.shape(label))
+	}
+	return strings.Join(labels, ".")
+}
+
+// maskQuery replaces the values of the selected parameters, the order of parameters is kept
+func (me MaskEngine) maskQuery(rawQuery string) string {
+	parameters := strings.Split(rawQuery, "&")
+	for i, parameter := range parameters {
+		parts := strings.SplitN(parameter, "=", 2)
+		if len(parts) != 2 || !me.selected(parts[0]) {
+			continue
+		}
+		value, err := url.QueryUnescape(parts[1])
+		if err != nil {
+			value = parts[1]
+		}
+		parameters[i] = parts[0] + "=" + url.QueryEscape(me.shape(value))
+	}
+	return strings.Join(parameters, "&")
+}
+
+func (me MaskEngine) selected(parameter string) bool {
+	name, err := url.QueryUnescape(parameter)
+	if err != nil {
+		name = parameter
+	}
+	for _, query := range me.query {
+		if query == "*" || query == name {
+			return true
+		}
+	}
+	return false
+}
+
+// shape replaces letters by random letters of the same case and digits by random digits, other characters are kept
+func (me MaskEngine) shape(s string) string {
+	var sb strings.Builder
+	for _, c := range s {
+		switch {
+		case strings.ContainsRune(lower, c):
+			sb.WriteByte(lower[me.rand.Intn(len(lower))])
+		case strings.ContainsRune(upper, c):
+			sb.WriteByte(upper[me.rand.Intn(len(upper))])
+		case strings.ContainsRune(digits, c):
+			sb.WriteByte(digits[me.rand.Intn(len(digits))])
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+// Factory create a mask from a yaml config
+func Factory(conf model.Masking, seed int64, caches map[string]model.Cache) (model.MaskEngine, bool, error) {
+	if conf.Mask.URL != nil {
+		// set differents seeds for differents jsonpath
+		h := fnv.New64a()
+		h.Write([]byte(conf.Selector.Jsonpath))
+		seed += int64(h.Sum64())
+		var addresses model.MaskEngine
+		if conf.Mask.URL.KeyFromEnv != "" {
+			key, err := ip.Key(conf.Mask.URL.KeyFromEnv)
+			if err != nil {
+				return nil, true, err
+			}
+			if addresses, err = ip.NewMask(key); err != nil {
+				return nil, true, err
+			}
+		}
+		return NewMask(conf.Mask.URL.Host, conf.Mask.URL.Path, conf.Mask.URL.Query, addresses, seed), true, nil
+	}
+	return nil, false, nil
+}
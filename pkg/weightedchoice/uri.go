@@ -0,0 +1,145 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package weightedchoice
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/cgi-fr/pimo/pkg/uri"
+	"github.com/rs/zerolog/log"
+)
+
+// URIMaskEngine is a mask choosing values by weight from a resource, the resource name is a template
+type URIMaskEngine struct {
+	model.RandState
+	template *template.Template
+	cache    map[string]Chooser
+}
+
+// NewURIMask create a URIMaskEngine, all choosers share the same random generator
+func NewURIMask(templateSource string, seed int64) (URIMaskEngine, error) {
+	template, err := template.New("template-weightedChoiceInUri").Parse(templateSource)
+	return URIMaskEngine{model.NewRandState(model.NewRandSource(seed)), template, map[string]Chooser{}}, err
+}
+
+// Mask choose a value by weight from the resource
+func (wum URIMaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.Entry, error) {
+	log.Info().Msg("Mask weightedChoiceInUri")
+	var output bytes.Buffer
+	if len(context) == 0 {
+		context = []model.Dictionary{model.NewDictionary()}
+	}
+	if err := wum.template.Execute(&output, context[0].Unordered()); err != nil {
+		return nil, err
+	}
+	filename := output.String()
+	chooser, ok := wum.cache[filename]
+	if !ok {
+		lines, err := uri.Read(filename)
+		if err != nil {
+			return nil, err
+		}
+		choices, err := ReadChoices(lines)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", filename, err.Error())
+		}
+		chooser = NewChooserFromSource(wum.Source(), choices...)
+		wum.cache[filename] = chooser
+	}
+	return chooser.Pick(), nil
+}
+
+// ReadChoices parses lines of a resource, each line is a CSV row value,weight (a first row without a numeric weight
+// is a header) or a JSON object {"choice": value, "weight": weight}
+func ReadChoices(lines []model.Entry) ([]Choice, error) {
+	choices := []Choice{}
+	total := 0
+	for i, line := range lines {
+		text, ok := line.(string)
+		if !ok {
+			return nil, fmt.Errorf("line %d has no weight", i+1)
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		var choice Choice
+		if strings.HasPrefix(strings.TrimSpace(text), "{") {
+			var definition struct {
+				Choice model.Entry `json:"choice"`
+				Weight uint        `json:"weight"`
+			}
+			if err := json.Unmarshal([]byte(text), &definition); err != nil {
+				return nil, fmt.Errorf("line %d: %s", i+1, err.Error())
+			}
+			choice = Choice{definition.Choice, definition.Weight}
+		} else {
+			record, err := csv.NewReader(strings.NewReader(text)).Read()
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", i+1, err.Error())
+			}
+			if len(record) != 2 {
+				return nil, fmt.Errorf("line %d should have 2 columns value,weight", i+1)
+			}
+			weight, err := strconv.ParseUint(strings.TrimSpace(record[1]), 10, 32)
+			if err != nil {
+				if i == 0 {
+					continue
+				}
+				return nil, fmt.Errorf("line %d: weight '%s' is not a positive integer", i+1, record[1])
+			}
+			var item interface{} = record[0]
+			// integers are read as numbers, like in other masks using resources
+			if number, err := strconv.Atoi(record[0]); err == nil {
+				item = number
+			}
+			choice = Choice{item, uint(weight)}
+		}
+
+		if choice.Weight == 0 {
+			continue
+		}
+		total += int(choice.Weight)
+		choices = append(choices, choice)
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("no choice with a positive weight")
+	}
+	return choices, nil
+}
+
+// URIFactory create a mask from a yaml config
+func URIFactory(conf model.Masking, seed int64, caches map[string]model.Cache) (model.MaskEngine, bool, error) {
+	if len(conf.Mask.WeightedChoiceInURI) != 0 {
+		// set differents seeds for differents jsonpath
+		h := fnv.New64a()
+		h.Write([]byte(conf.Selector.Jsonpath))
+		seed += int64(h.Sum64())
+		mask, err := NewURIMask(conf.Mask.WeightedChoiceInURI, seed)
+		return mask, true, err
+	}
+	return nil, false, nil
+}
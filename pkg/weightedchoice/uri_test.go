@@ -0,0 +1,103 @@
+// Copyright (C) 2021 CGI France
+//
+// This file is part of PIMO.
+//
+// PIMO is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// PIMO is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with PIMO.  If not, see <http://www.gnu.org/licenses/>.
+
+package weightedchoice
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cgi-fr/pimo/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadChoicesShouldParseCSVAndJSONLines(t *testing.T) {
+	choices, err := ReadChoices([]model.Entry{
+		"value,weight",
+		"Paris,2148",
+		`"Saint-Denis, La Réunion",153`,
+		"",
+		"75,0",
+		`{"choice": "Nantes", "weight": 314}`,
+		`{"choice": 44, "weight": 1}`,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []Choice{
+		{"Paris", 2148},
+		{"Saint-Denis, La Réunion", 153},
+		{"Nantes", 314},
+		{float64(44), 1},
+	}, choices)
+}
+
+func TestReadChoicesShouldFailOnInvalidLines(t *testing.T) {
+	for _, lines := range [][]model.Entry{
+		{"Paris,1", "Nantes"},
+		{"Paris,1", "Nantes,many"},
+		{"Paris,1", 12},
+		{"Paris,0"},
+		{`{"choice": "Paris", "weight": -1}`},
+	} {
+		_, err := ReadChoices(lines)
+		assert.NotNil(t, err, "%v", lines)
+	}
+}
+
+func TestURIMaskShouldChooseByWeight(t *testing.T) {
+	dir, err := ioutil.TempDir("", "weighted")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "FR.csv"), []byte("Paris,1\nNantes,0\n"), 0o600))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "BE.csv"), []byte("Liège,5\n"), 0o600))
+
+	mask, err := NewURIMask("file://"+dir+"/{{.country}}.csv", 42)
+	assert.Nil(t, err)
+
+	result, err := mask.Mask("x", model.NewDictionary().With("country", "FR"))
+	assert.Nil(t, err)
+	assert.Equal(t, "Paris", result)
+	result, err = mask.Mask("x", model.NewDictionary().With("country", "BE"))
+	assert.Nil(t, err)
+	assert.Equal(t, "Liège", result)
+	assert.Len(t, mask.cache, 2)
+
+	_, err = mask.Mask("x", model.NewDictionary().With("country", "DE"))
+	assert.NotNil(t, err)
+}
+
+func TestURIMaskShouldBeDeterministic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "weighted")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "towns.csv"), []byte("Paris,50\nNantes,30\nRennes,20\n"), 0o600))
+
+	conf := model.Masking{Selector: model.SelectorType{Jsonpath: "town"}, Mask: model.MaskType{WeightedChoiceInURI: "file://" + dir + "/towns.csv"}}
+	first, present, err := URIFactory(conf, 42, nil)
+	assert.True(t, present)
+	assert.Nil(t, err)
+	second, _, _ := URIFactory(conf, 42, nil)
+
+	for i := 0; i < 20; i++ {
+		a, err := first.Mask(nil)
+		assert.Nil(t, err)
+		b, err := second.Mask(nil)
+		assert.Nil(t, err)
+		assert.Equal(t, a, b)
+	}
+}
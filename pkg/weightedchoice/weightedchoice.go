@@ -40,10 +40,16 @@ type Chooser struct {
 	totals []int
 	max    int
 	rand   *rand.Rand
+	model.RandState
 }
 
 // NewChooser creates a Chooser from Choices
 func NewChooser(seed int64, cs ...Choice) Chooser {
+	return NewChooserFromSource(model.NewRandSource(seed), cs...)
+}
+
+// NewChooserFromSource creates a Chooser from Choices using a shared random generator
+func NewChooserFromSource(source *model.RandSource, cs ...Choice) Chooser {
 	sort.Slice(cs, func(i, j int) bool {
 		return cs[i].Weight < cs[j].Weight
 	})
@@ -54,7 +60,7 @@ func NewChooser(seed int64, cs ...Choice) Chooser {
 		totals[i] = runningTotal
 	}
 	// nolint: gosec
-	return Chooser{data: cs, totals: totals, max: runningTotal, rand: rand.New(rand.NewSource(seed))}
+	return Chooser{data: cs, totals: totals, max: runningTotal, rand: rand.New(source), RandState: model.NewRandState(source)}
 }
 
 // Pick returns a choice from a Chooser
@@ -66,7 +72,7 @@ func (chs Chooser) Pick() interface{} {
 
 // MaskEngine a list of masking value with weight for random
 type MaskEngine struct {
-	cs Chooser
+	Chooser
 }
 
 // NewMask returns a WeightedMaskList from slice of model.Entry and weights
@@ -82,7 +88,7 @@ func NewMask(list []model.WeightedChoiceType, seed int64) MaskEngine {
 // Mask choose a mask value randomly
 func (wml MaskEngine) Mask(e model.Entry, context ...model.Dictionary) (model.Entry, error) {
 	log.Info().Msg("Mask weightedChoice")
-	return wml.cs.Pick(), nil
+	return wml.Chooser.Pick(), nil
 }
 
 // Factory create a mask from a yaml config